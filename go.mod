@@ -1,8 +1,10 @@
 module github.com/theblackturtle/ffuf
 
-go 1.11
+go 1.13
 
 require (
 	github.com/json-iterator/go v1.1.9
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/valyala/fasthttp v1.15.1
+	golang.org/x/net v0.0.0-20200602114024-627f9648deb9
 )
@@ -54,14 +54,14 @@ func Usage() {
         Description:   "Options controlling the HTTP request and its parts.",
         Flags:         make([]UsageFlag, 0),
         Hidden:        false,
-        ExpectedFlags: []string{"H", "X", "b", "d", "r", "u", "recursion", "recursion-depth", "replay-proxy", "timeout", "ignore-body", "x"},
+        ExpectedFlags: []string{"H", "X", "b", "d", "h2c", "http2", "r", "runner", "u", "recursion", "recursion-depth", "replay-proxy", "replay-marker", "retries", "retry-delay", "retry-on", "timeout", "ignore-body", "x"},
     }
     u_general := UsageSection{
         Name:          "GENERAL OPTIONS",
         Description:   "",
         Flags:         make([]UsageFlag, 0),
         Hidden:        false,
-        ExpectedFlags: []string{"ac", "acc", "c", "maxtime", "maxtime-job", "p", "s", "sa", "se", "sf", "t", "v", "V"},
+        ExpectedFlags: []string{"ac", "acc", "acj", "acs", "c", "maxtime", "maxtime-job", "p", "rate", "rate-per-host", "adaptive", "s", "sa", "se", "sf", "t", "v", "V"},
     }
     u_compat := UsageSection{
         Name:          "COMPATIBILITY OPTIONS",
@@ -75,28 +75,28 @@ func Usage() {
         Description:   "Matchers for the response filtering.",
         Flags:         make([]UsageFlag, 0),
         Hidden:        false,
-        ExpectedFlags: []string{"mc", "ml", "mr", "ms", "mw"},
+        ExpectedFlags: []string{"mc", "mexpr", "mh", "mjson", "ml", "mmode", "mr", "mrefl", "ms", "mt", "mw"},
     }
     u_filter := UsageSection{
         Name:          "FILTER OPTIONS",
         Description:   "Filters for the response filtering.",
         Flags:         make([]UsageFlag, 0),
         Hidden:        false,
-        ExpectedFlags: []string{"fc", "fl", "fr", "fs", "fw"},
+        ExpectedFlags: []string{"fc", "fexpr", "fh", "fjson", "fl", "fmode", "fr", "frefl", "fs", "ft", "fw"},
     }
     u_input := UsageSection{
         Name:          "INPUT OPTIONS",
         Description:   "Options for input data for fuzzing. Wordlists and input generators.",
         Flags:         make([]UsageFlag, 0),
         Hidden:        false,
-        ExpectedFlags: []string{"D", "ic", "input-cmd", "input-num", "mode", "request", "request-proto", "e", "w"},
+        ExpectedFlags: []string{"D", "ic", "input-cmd", "input-default", "input-num", "input-stream", "mode", "replay-errors", "request", "request-proto", "e", "stream", "w"},
     }
     u_output := UsageSection{
         Name:          "OUTPUT OPTIONS",
         Description:   "Options for output. Output file formats, file names and debug file locations.",
         Flags:         make([]UsageFlag, 0),
         Hidden:        false,
-        ExpectedFlags: []string{"debug-log", "o", "of", "od"},
+        ExpectedFlags: []string{"debug-log", "dedup", "o", "oe", "of", "od"},
     }
     sections := []UsageSection{u_http, u_general, u_compat, u_matcher, u_filter, u_input, u_output}
 
@@ -147,6 +147,10 @@ func Usage() {
     fmt.Printf("  Fuzz multiple locations. Match only responses reflecting the value of \"VAL\" keyword. Colored.\n")
     fmt.Printf("    ffuf -w params.txt:PARAM -w values.txt:VAL -u https://example.org/?PARAM=VAL -mr \"VAL\" -c\n\n")
 
+    fmt.Printf("  Try the same payloads in both positions of a request in turn, the other position keeping its original value.\n")
+    fmt.Printf("    ffuf -request req.txt -mode sniper -w payloads.txt:P1 -w payloads.txt:P2 \\\n")
+    fmt.Printf("      -input-default P1:admin -input-default P2:secret\n\n")
+
     fmt.Printf("  More information and examples: https://github.com/ffuf/ffuf\n\n")
 }
 
@@ -0,0 +1,181 @@
+package ffuf
+
+import (
+    "math/rand"
+    "strings"
+)
+
+// Amount of values read ahead from the input of a keyword to find out its shape
+const calibrationSampleSize = 100
+
+// calibrationShape describes the values of a keyword, so the calibration probes look like them
+type calibrationShape struct {
+    keyword    string
+    numeric    bool
+    width      int
+    path       bool
+    extensions []string
+    sample     []byte
+}
+
+// calibrationInputs returns the inputs for the calibration requests. The custom calibration
+// strings (-acc) are used for all of the keywords at once. Otherwise every keyword gets probes
+// shaped like its own wordlist, sent both with random and real values in the other positions.
+func (j *Job) calibrationInputs() []map[string][]byte {
+    inputs := make([]map[string][]byte, 0)
+    if len(j.Config.AutoCalibrationStrings) > 0 {
+        for _, input := range j.Config.AutoCalibrationStrings {
+            values := make(map[string][]byte)
+            for _, v := range j.Config.InputProviders {
+                values[v.Keyword] = []byte(input)
+            }
+            inputs = append(inputs, values)
+        }
+        return inputs
+    }
+
+    shapes := make([]calibrationShape, 0)
+    for _, v := range j.Config.InputProviders {
+        shapes = append(shapes, j.calibrationShape(v.Keyword))
+    }
+    for i, shape := range shapes {
+        for _, probe := range shape.probes() {
+            // The other positions get a random value of their own shape
+            values := map[string][]byte{shape.keyword: []byte(probe)}
+            for k, other := range shapes {
+                if k != i {
+                    values[other.keyword] = []byte(other.random())
+                }
+            }
+            inputs = append(inputs, values)
+            if len(shapes) == 1 {
+                continue
+            }
+            // ...and then a real value from their wordlists, as a valid value in one position often
+            // changes the response for the others, eg. an existing username with a wrong password
+            real := map[string][]byte{shape.keyword: []byte(probe)}
+            hasReal := false
+            for k, other := range shapes {
+                if k == i {
+                    continue
+                }
+                if len(other.sample) > 0 {
+                    real[other.keyword] = other.sample
+                    hasReal = true
+                } else {
+                    real[other.keyword] = values[other.keyword]
+                }
+            }
+            if hasReal {
+                inputs = append(inputs, real)
+            }
+        }
+    }
+    return inputs
+}
+
+// calibrationShape reads ahead the values of a keyword to find out the shape of its wordlist
+func (j *Job) calibrationShape(keyword string) calibrationShape {
+    shape := calibrationShape{keyword: keyword}
+    if keyword == "FUZZ" || j.Config.DirSearchCompat {
+        // The wordlist input applies the extensions to the FUZZ keyword only, or replaces %EXT% with them
+        shape.extensions = j.Config.Extensions
+    }
+    shape.path = keywordInPath(j.Config.Url, keyword)
+    var samples [][]byte
+    if j.Input != nil {
+        samples = j.Input.Sample(keyword, calibrationSampleSize)
+    }
+    if len(samples) > 0 {
+        shape.sample = samples[0]
+    }
+    shape.numeric = len(samples) > 0
+    for _, s := range samples {
+        value := string(s)
+        for _, ext := range shape.extensions {
+            if ext != "" && strings.HasSuffix(value, ext) {
+                value = strings.TrimSuffix(value, ext)
+                break
+            }
+        }
+        if !isNumeric(value) {
+            shape.numeric = false
+            break
+        }
+        if len(value) > shape.width {
+            shape.width = len(value)
+        }
+    }
+    return shape
+}
+
+// probes returns the calibration values for the keyword
+func (s calibrationShape) probes() []string {
+    probes := make([]string, 0)
+    if s.numeric {
+        probes = append(probes, s.random(), s.random())
+    } else {
+        probes = append(probes, RandomString(16))
+        probes = append(probes, ".htaccess"+RandomString(16))
+        probes = append(probes, RandomString(6)+"`z'z\"${{%{{\\")
+        probes = append(probes, RandomString(6)+"\\z`z'z\"${{%{{\\")
+    }
+    if s.path {
+        probes = append(probes, "admin"+RandomString(16)+"/")
+        probes = append(probes, s.random()+"/")
+    }
+    for _, ext := range s.extensions {
+        if ext != "" {
+            probes = append(probes, s.random()+ext)
+        }
+    }
+    return probes
+}
+
+// random returns a random value shaped like the wordlist of the keyword
+func (s calibrationShape) random() string {
+    if !s.numeric {
+        return RandomString(16)
+    }
+    width := s.width
+    if width < 6 {
+        // Short numbers likely exist, prefer values outside of the range of the wordlist
+        width = 6
+    }
+    n := make([]byte, width)
+    for i := range n {
+        n[i] = byte('0' + rand.Intn(10))
+    }
+    if s.width < 6 || n[0] == '0' {
+        n[0] = byte('1' + rand.Intn(9))
+    }
+    return string(n)
+}
+
+// keywordInPath returns true if the keyword is in the path part of the URL
+func keywordInPath(url string, keyword string) bool {
+    if i := strings.Index(url, "://"); i != -1 {
+        url = url[i+3:]
+    }
+    i := strings.Index(url, "/")
+    if i == -1 {
+        return false
+    }
+    path := url[i:]
+    if q := strings.IndexAny(path, "?#"); q != -1 {
+        path = path[:q]
+    }
+    return strings.Contains(path, keyword)
+}
+
+func isNumeric(value string) bool {
+    if len(value) == 0 {
+        return false
+    }
+    for _, c := range value {
+        if c < '0' || c > '9' {
+            return false
+        }
+    }
+    return true
+}
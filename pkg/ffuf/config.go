@@ -16,27 +16,48 @@ type Config struct {
     InputProviders         []InputProviderConfig     `json:"inputproviders"`
     CommandKeywords        []string                  `json:"-"`
     InputNum               int                       `json:"cmd_inputnum"`
+    InputStream            string                    `json:"cmd_inputstream"`
     InputMode              string                    `json:"inputmode"`
+    InputDefaults          map[string]string         `json:"input_defaults"`
     OutputDirectory        string                    `json:"outputdirectory"`
     OutputFile             string                    `json:"outputfile"`
-    OutputFormat           string                    `json:"outputformat"`
+    OutputFormats          []string                  `json:"outputformats"`
+    OutputErrors           bool                      `json:"outputerrors"`
+    Dedup                  bool                      `json:"dedup"`
     IgnoreBody             bool                      `json:"ignorebody"`
     IgnoreWordlistComments bool                      `json:"ignore_wordlist_comments"`
+    StreamWordlists        bool                      `json:"stream_wordlists"`
     StopOn403              bool                      `json:"stop_403"`
     StopOnErrors           bool                      `json:"stop_errors"`
     StopOnAll              bool                      `json:"stop_all"`
     FollowRedirects        bool                      `json:"follow_redirects"`
     AutoCalibration        bool                      `json:"autocalibration"`
     AutoCalibrationStrings []string                  `json:"autocalibration_strings"`
+    CalibrationThreshold   float64                   `json:"autocalibration_threshold"`
+    CalibrationJSON        bool                      `json:"autocalibration_json"`
     Timeout                int                       `json:"timeout"`
+    Retries                int                       `json:"retries"`
+    RetryDelay             float64                   `json:"retry_delay"`
+    RetryErrors            []string                  `json:"retry_errors"`
+    RetryStatuses          []ValueRange              `json:"retry_statuses"`
     ProgressFrequency      int                       `json:"-"`
     Delay                  optRange                  `json:"delay"`
-    Filters                map[string]FilterProvider `json:"filters"`
-    Matchers               map[string]FilterProvider `json:"matchers"`
+    Rate                   int                       `json:"rate"`
+    RatePerHost            bool                      `json:"rate_per_host"`
+    AdaptiveThrottle       bool                      `json:"adaptive_throttle"`
+    Filters                []FilterProvider          `json:"filters"`
+    Matchers               []FilterProvider          `json:"matchers"`
+    CalibrationFilters     []FilterProvider          `json:"calibration_filters"`
+    FilterMode             string                    `json:"fmode"`
+    MatcherMode            string                    `json:"mmode"`
     Threads                int                       `json:"threads"`
     Context                context.Context           `json:"-"`
     ProxyURL               string                    `json:"proxyurl"`
     ReplayProxyURL         string                    `json:"replayproxyurl"`
+    ReplayMarker           string                    `json:"replaymarker"`
+    RawRequest             string                    `json:"-"`
+    Runner                 string                    `json:"runner"`
+    H2C                    bool                      `json:"h2c"`
     CommandLine            string                    `json:"cmdline"`
     Verbose                bool                      `json:"verbose"`
     MaxTime                int                       `json:"maxtime"`
@@ -49,6 +70,7 @@ type InputProviderConfig struct {
     Name    string `json:"name"`
     Keyword string `json:"keyword"`
     Value   string `json:"value"`
+    Columns string `json:"columns,omitempty"`
 }
 
 func NewConfig(ctx context.Context) Config {
@@ -64,17 +86,33 @@ func NewConfig(ctx context.Context) Config {
     conf.StopOnErrors = false
     conf.StopOnAll = false
     conf.FollowRedirects = false
+    conf.OutputFormats = make([]string, 0)
+    conf.OutputErrors = false
     conf.InputProviders = make([]InputProviderConfig, 0)
     conf.CommandKeywords = make([]string, 0)
     conf.AutoCalibrationStrings = make([]string, 0)
     conf.InputNum = 0
     conf.InputMode = "clusterbomb"
+    conf.InputDefaults = make(map[string]string)
     conf.ProxyURL = ""
-    conf.Filters = make(map[string]FilterProvider)
-    conf.Matchers = make(map[string]FilterProvider)
+    conf.ReplayMarker = ""
+    conf.Runner = "http"
+    conf.H2C = false
+    conf.Filters = make([]FilterProvider, 0)
+    conf.Matchers = make([]FilterProvider, 0)
+    conf.CalibrationFilters = make([]FilterProvider, 0)
+    conf.FilterMode = "or"
+    conf.MatcherMode = "or"
     conf.Delay = optRange{0, 0, false, false}
+    conf.Rate = 0
+    conf.RatePerHost = false
+    conf.AdaptiveThrottle = false
     conf.Extensions = make([]string, 0)
     conf.Timeout = 10
+    conf.Retries = 1
+    conf.RetryDelay = 0
+    conf.RetryErrors = make([]string, 0)
+    conf.RetryStatuses = make([]ValueRange, 0)
     // Progress update frequency, in milliseconds
     conf.ProgressFrequency = 100
     conf.DirSearchCompat = false
@@ -0,0 +1,61 @@
+package ffuf
+
+import (
+    "crypto/x509"
+    "errors"
+    "io"
+    "net"
+    "strings"
+    "syscall"
+
+    "github.com/valyala/fasthttp"
+)
+
+const (
+    ErrorClassTimeout = "timeout"
+    ErrorClassReset   = "reset"
+    ErrorClassRefused = "refused"
+    ErrorClassTLS     = "tls"
+    ErrorClassDNS     = "dns"
+    ErrorClassOther   = "other"
+)
+
+// ErrorClasses lists all the request error classes, in the order they are displayed
+var ErrorClasses = []string{ErrorClassTimeout, ErrorClassReset, ErrorClassRefused, ErrorClassTLS, ErrorClassDNS, ErrorClassOther}
+
+// ClassifyError returns the class of a request error returned by a RunnerProvider
+func ClassifyError(err error) string {
+    var netErr net.Error
+    var dnsErr *net.DNSError
+    var certErr x509.CertificateInvalidError
+    var authErr x509.UnknownAuthorityError
+    var hostErr x509.HostnameError
+    switch {
+    case errors.As(err, &dnsErr):
+        return ErrorClassDNS
+    case errors.Is(err, syscall.ECONNREFUSED):
+        return ErrorClassRefused
+    case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE), errors.Is(err, io.EOF),
+        errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, fasthttp.ErrConnectionClosed):
+        return ErrorClassReset
+    case errors.Is(err, fasthttp.ErrDialTimeout), errors.As(err, &netErr) && netErr.Timeout():
+        return ErrorClassTimeout
+    case errors.As(err, &certErr), errors.As(err, &authErr), errors.As(err, &hostErr):
+        return ErrorClassTLS
+    }
+    // Fall back to the error message for errors not wrapped by the runners
+    msg := strings.ToLower(err.Error())
+    switch {
+    case strings.Contains(msg, "no such host"):
+        return ErrorClassDNS
+    case strings.Contains(msg, "connection refused"):
+        return ErrorClassRefused
+    case strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"), strings.Contains(msg, "closed connection"):
+        return ErrorClassReset
+    case strings.Contains(msg, "timeout"), strings.Contains(msg, "timed out"):
+        return ErrorClassTimeout
+    case strings.Contains(msg, "tls"), strings.Contains(msg, "x509"), strings.Contains(msg, "certificate"):
+        return ErrorClassTLS
+    }
+    return ErrorClassOther
+}
@@ -0,0 +1,55 @@
+package ffuf
+
+import (
+    "context"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "os"
+    "syscall"
+    "testing"
+
+    "github.com/valyala/fasthttp"
+)
+
+func TestClassifyError(t *testing.T) {
+    dialErr := func(err error) error {
+        return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+    }
+    for _, test := range []struct {
+        name string
+        err  error
+        want string
+    }{
+        {name: "dial timeout", err: fasthttp.ErrDialTimeout, want: ErrorClassTimeout},
+        {name: "read timeout", err: fasthttp.ErrTimeout, want: ErrorClassTimeout},
+        {name: "net/http timeout", err: &url.Error{Op: "Get", URL: "http://target/", Err: context.DeadlineExceeded}, want: ErrorClassTimeout},
+        {name: "context deadline", err: context.DeadlineExceeded, want: ErrorClassTimeout},
+        {name: "timeout message", err: errors.New("i/o timeout"), want: ErrorClassTimeout},
+        {name: "refused", err: dialErr(syscall.ECONNREFUSED), want: ErrorClassRefused},
+        {name: "refused message", err: errors.New("dial tcp 127.0.0.1:1: connect: connection refused"), want: ErrorClassRefused},
+        {name: "reset", err: dialErr(syscall.ECONNRESET), want: ErrorClassReset},
+        {name: "broken pipe", err: dialErr(syscall.EPIPE), want: ErrorClassReset},
+        {name: "eof", err: io.EOF, want: ErrorClassReset},
+        {name: "unexpected eof", err: fmt.Errorf("reading the response: %w", io.ErrUnexpectedEOF), want: ErrorClassReset},
+        {name: "closed by the server", err: fasthttp.ErrConnectionClosed, want: ErrorClassReset},
+        {name: "dns", err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "target.invalid"}}, want: ErrorClassDNS},
+        {name: "dns message", err: errors.New("lookup target.invalid: no such host"), want: ErrorClassDNS},
+        {name: "unknown authority", err: &url.Error{Op: "Get", URL: "https://target/", Err: x509.UnknownAuthorityError{}}, want: ErrorClassTLS},
+        {name: "hostname", err: x509.HostnameError{Certificate: &x509.Certificate{}, Host: "target"}, want: ErrorClassTLS},
+        {name: "tls message", err: errors.New("remote error: tls: handshake failure"), want: ErrorClassTLS},
+        {name: "proxy refused", err: &net.OpError{Op: "proxyconnect", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, want: ErrorClassRefused},
+        {name: "proxy status", err: errors.New("CONNECT to target:443 failed with status 407"), want: ErrorClassOther},
+        {name: "socks5 failure", err: errors.New("SOCKS5 connect to target:443 failed with code 5"), want: ErrorClassOther},
+        {name: "context cancelled", err: context.Canceled, want: ErrorClassOther},
+        {name: "cancelled request", err: &url.Error{Op: "Get", URL: "http://target/", Err: context.Canceled}, want: ErrorClassOther},
+        {name: "other", err: errors.New("too many redirects"), want: ErrorClassOther},
+    } {
+        if class := ClassifyError(test.err); class != test.want {
+            t.Errorf("%s: expected the class %s for %q, got %s", test.name, test.want, test.err, class)
+        }
+    }
+}
@@ -0,0 +1,36 @@
+package ffuf
+
+import (
+    "bytes"
+    "crypto/sha1"
+    "fmt"
+    "sort"
+)
+
+// ResponseHash returns a hash of the status code and the normalized body of the response. The
+// input values reflected in the body, raw or encoded, are removed and the whitespace is collapsed,
+// so the responses differing only by the echoed input get the same hash.
+func ResponseHash(resp *Response) string {
+    body := resp.Data
+    if resp.Request != nil {
+        forms := make([][]byte, 0)
+        for _, v := range resp.Request.Input {
+            if len(v) < MinReflectionLength {
+                continue
+            }
+            for _, f := range reflectionForms(string(v)) {
+                forms = append(forms, f.value)
+            }
+        }
+        // Remove the longest forms first, so the shorter ones do not break them apart
+        sort.Slice(forms, func(i, j int) bool { return len(forms[i]) > len(forms[j]) })
+        for _, f := range forms {
+            body = bytes.ReplaceAll(body, f, []byte{})
+        }
+    }
+    body = bytes.Join(bytes.Fields(body), []byte(" "))
+    h := sha1.New()
+    fmt.Fprintf(h, "%d\n", resp.StatusCode)
+    h.Write(body)
+    return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -19,7 +19,10 @@ type InputProvider interface {
     Position() int
     Reset()
     Value() map[string][]byte
+    Active() string
     Total() int
+    Sample(keyword string, count int) [][]byte
+    Close()
 }
 
 // InternalInputProvider interface handles providing input data to InputProvider
@@ -42,4 +45,5 @@ type OutputProvider interface {
     Error(errstring string)
     Warning(warnstring string)
     Result(resp Response)
+    ErrorResult(req Request, errclass string, err error)
 }
@@ -4,13 +4,18 @@ import (
     "fmt"
     "log"
     "math/rand"
+    "net/url"
     "os"
     "os/signal"
+    "strings"
     "sync"
     "syscall"
     "time"
 )
 
+// Upper limit for the retry backoff, in seconds
+const maxRetryBackoff = 30.0
+
 // Job ties together Config, Runner, Input and Output
 type Job struct {
     Config               *Config
@@ -19,8 +24,12 @@ type Job struct {
     Runner               RunnerProvider
     ReplayRunner         RunnerProvider
     Output               OutputProvider
+    Calibrate            func(j *Job) error
+    Rate                 *RateThrottle
+    Throttle             *AdaptiveThrottle
     Counter              int
     ErrorCounter         int
+    ErrorClassCounter    map[string]int
     SpuriousErrorCounter int
     Total                int
     Running              bool
@@ -44,6 +53,7 @@ func NewJob(conf *Config) Job {
     var j Job
     j.Counter = 0
     j.ErrorCounter = 0
+    j.ErrorClassCounter = make(map[string]int)
     j.SpuriousErrorCounter = 0
     j.Running = false
     j.RunningJob = false
@@ -53,12 +63,27 @@ func NewJob(conf *Config) Job {
     return j
 }
 
-// incError increments the error counter
-func (j *Job) incError() {
+// incError increments the error counter and the counter of the error class
+func (j *Job) incError(class string) {
     j.ErrorMutex.Lock()
     defer j.ErrorMutex.Unlock()
     j.ErrorCounter++
     j.SpuriousErrorCounter++
+    if j.ErrorClassCounter == nil {
+        j.ErrorClassCounter = make(map[string]int)
+    }
+    j.ErrorClassCounter[class]++
+}
+
+// errorClassCounts returns a copy of the error counters by class
+func (j *Job) errorClassCounts() map[string]int {
+    j.ErrorMutex.Lock()
+    defer j.ErrorMutex.Unlock()
+    counts := make(map[string]int, len(j.ErrorClassCounter))
+    for k, v := range j.ErrorClassCounter {
+        counts[k] = v
+    }
+    return counts
 }
 
 // inc403 increments the 403 response counter
@@ -93,6 +118,7 @@ func (j *Job) Start() {
     rand.Seed(time.Now().UnixNano())
     j.Total = j.Input.Total()
     defer j.Stop()
+    defer j.Input.Close()
 
     j.Running = true
     j.RunningJob = true
@@ -102,6 +128,7 @@ func (j *Job) Start() {
     }
     // Monitor for SIGTERM and do cleanup properly (writing the output files etc)
     j.interruptMonitor()
+    j.rateMonitor()
     for j.jobsInQueue() {
         j.prepareQueueJob()
 
@@ -109,6 +136,9 @@ func (j *Job) Start() {
             // Print info for queued recursive jobs
             j.Output.Info(fmt.Sprintf("Scanning: %s", j.Config.Url))
         }
+        if j.queuepos > 1 {
+            j.calibrateQueueJob()
+        }
         j.Input.Reset()
         j.startTimeJob = time.Now()
         j.RunningJob = true
@@ -132,10 +162,34 @@ func (j *Job) prepareQueueJob() {
     j.queuepos += 1
 }
 
+// calibrateQueueJob runs the auto-calibration again for a queued job, as the error pages of a
+// subdirectory often differ from the ones of its parent. The filters of the previous job are dropped.
+func (j *Job) calibrateQueueJob() {
+    if !j.Config.AutoCalibration || j.Calibrate == nil {
+        return
+    }
+    j.Config.CalibrationFilters = make([]FilterProvider, 0)
+    if err := j.Calibrate(j); err != nil {
+        j.Output.Error(fmt.Sprintf("Error in autocalibration for %s: %s", j.Config.Url, err))
+        return
+    }
+    if len(j.Config.CalibrationFilters) == 0 {
+        j.Output.Info(fmt.Sprintf("Calibration for %s: no filters", j.Config.Url))
+        return
+    }
+    filters := make([]string, 0)
+    for _, f := range j.Config.CalibrationFilters {
+        filters = append(filters, f.Repr())
+    }
+    j.Output.Info(fmt.Sprintf("Calibration for %s: %s", j.Config.Url, strings.Join(filters, ", ")))
+}
+
 func (j *Job) startExecution() {
     var wg sync.WaitGroup
+    // Closed when the input ends, the progress is then updated once all of the tasks are done
+    inputDone := make(chan struct{})
     wg.Add(1)
-    go j.runProgress(&wg)
+    go j.runProgress(&wg, inputDone)
     // Limiter blocks after reaching the buffer, ensuring limited concurrency
     limiter := make(chan bool, j.Config.Threads)
 
@@ -151,12 +205,13 @@ func (j *Job) startExecution() {
         limiter <- true
         nextInput := j.Input.Value()
         nextPosition := j.Input.Position()
+        nextActive := j.Input.Active()
         wg.Add(1)
         j.Counter++
         go func() {
             defer func() { <-limiter }()
             defer wg.Done()
-            j.runTask(nextInput, nextPosition, false)
+            j.runTask(nextInput, nextPosition, nextActive)
             if j.Config.Delay.HasDelay {
                 var sleepDurationMS time.Duration
                 if j.Config.Delay.IsRange {
@@ -174,6 +229,7 @@ func (j *Job) startExecution() {
             return
         }
     }
+    close(inputDone)
     wg.Wait()
     j.updateProgress()
     return
@@ -190,25 +246,80 @@ func (j *Job) interruptMonitor() {
     }()
 }
 
-func (j *Job) runProgress(wg *sync.WaitGroup) {
+// rateMonitor changes the request rate limit at runtime: SIGUSR1 doubles and SIGUSR2 halves it
+func (j *Job) rateMonitor() {
+    if j.Rate == nil || rateIncreaseSignal == nil {
+        return
+    }
+    sigChan := make(chan os.Signal, 2)
+    signal.Notify(sigChan, rateIncreaseSignal, rateDecreaseSignal)
+    go func() {
+        for sig := range sigChan {
+            rate := j.Rate.Rate()
+            if sig == rateIncreaseSignal {
+                if rate == 0 {
+                    // Already unlimited
+                    continue
+                }
+                rate *= 2
+            } else {
+                if rate == 0 {
+                    // Start limiting from the measured rate
+                    rate = j.measuredRate()
+                }
+                rate /= 2
+            }
+            if rate < 1 {
+                rate = 1
+            }
+            j.Rate.SetRate(rate)
+            j.Output.Info(fmt.Sprintf("Request rate limit set to %d req/sec", rate))
+        }
+    }()
+}
+
+// measuredRate returns the requests per second of the current job
+func (j *Job) measuredRate() int {
+    runningSecs := int(time.Now().Sub(j.startTimeJob) / time.Second)
+    if runningSecs < 1 {
+        return j.Counter
+    }
+    return j.Counter / runningSecs
+}
+
+// waitRate blocks until the rate limit allows sending the request
+func (j *Job) waitRate(req *Request) {
+    if j.Rate == nil {
+        return
+    }
+    host := ""
+    if u, err := url.Parse(req.Url); err == nil {
+        host = u.Host
+    }
+    j.Rate.Wait(host)
+}
+
+func (j *Job) runProgress(wg *sync.WaitGroup, inputDone <-chan struct{}) {
     defer wg.Done()
-    totalProgress := j.Input.Total()
-    for j.Counter <= totalProgress {
+    for {
 
         if !j.Running {
             break
         }
 
         j.updateProgress()
-        if j.Counter == totalProgress {
-            return
-        }
 
         if !j.RunningJob {
             return
         }
 
-        time.Sleep(time.Millisecond * time.Duration(j.Config.ProgressFrequency))
+        // The total of the streamed input commands is unknown or only a hint, so update until the
+        // input ends. The final update is left to startExecution.
+        select {
+        case <-inputDone:
+            return
+        case <-time.After(time.Millisecond * time.Duration(j.Config.ProgressFrequency)):
+        }
     }
 }
 
@@ -220,54 +331,106 @@ func (j *Job) updateProgress() {
         QueuePos:   j.queuepos,
         QueueTotal: len(j.queuejobs),
         ErrorCount: j.ErrorCounter,
+        ErrorClass: j.errorClassCounts(),
+    }
+    if j.Rate != nil {
+        prog.RateLimit = j.Rate.Rate()
+    }
+    if j.Throttle != nil {
+        prog.ThreadLimit = j.Throttle.Threads()
     }
     j.Output.Progress(prog)
 }
 
-func (j *Job) isMatch(resp Response) bool {
-    matched := false
-    for _, m := range j.Config.Matchers {
-        match, err := m.Filter(&resp)
-        if err != nil {
-            continue
-        }
-        if match {
-            matched = true
-        }
-    }
+func (j *Job) isMatch(resp *Response) bool {
     // The response was not matched, return before running filters
-    if !matched {
+    if !matchRules(j.Config.Matchers, j.Config.MatcherMode, resp) {
+        return false
+    }
+    if matchRules(j.Config.Filters, j.Config.FilterMode, resp) {
+        return false
+    }
+    // Auto-calibration filters drop the response on their own, regardless of -fmode
+    if matchRules(j.Config.CalibrationFilters, "or", resp) {
+        return false
+    }
+    return true
+}
+
+// matchRules returns true if the response matches all of the rules in "and" mode, or any of them in
+// "or" mode. A rule returning an error does not match, and an empty list of rules never matches.
+func matchRules(rules []FilterProvider, mode string, resp *Response) bool {
+    if len(rules) == 0 {
         return false
     }
-    for _, f := range j.Config.Filters {
-        fv, err := f.Filter(&resp)
+    for _, r := range rules {
+        match, err := r.Filter(resp)
         if err != nil {
-            continue
+            match = false
+        }
+        if match && mode != "and" {
+            return true
         }
-        if fv {
+        if !match && mode == "and" {
             return false
         }
     }
-    return true
+    return mode == "and"
 }
 
-func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
+// execute sends the request when the rate limit allows it. With adaptive throttling, the request
+// is re-sent after a pause if the target responded with a throttling response.
+func (j *Job) execute(req *Request) (Response, error) {
+    for requeues := 0; ; requeues++ {
+        j.waitRate(req)
+        if j.Throttle == nil {
+            return j.Runner.Execute(req)
+        }
+        j.Throttle.Acquire()
+        resp, err := j.Runner.Execute(req)
+        j.Throttle.Release()
+        if err != nil {
+            return resp, err
+        }
+        if !IsThrottleResponse(resp) {
+            j.Throttle.Success()
+            return resp, err
+        }
+        if requeues >= MaxThrottleRequeues {
+            return resp, err
+        }
+        pause, cut := j.Throttle.Throttled(RetryAfter(resp), j.measuredRate())
+        if cut {
+            j.Output.Warning(fmt.Sprintf("Target is throttling (status %d), pausing for %s. Threads: %d, rate limit: %d req/sec\n", resp.StatusCode, pause.Round(time.Second), j.Throttle.Threads(), j.Rate.Rate()))
+        }
+    }
+}
+
+func (j *Job) runTask(input map[string][]byte, position int, active string) {
     req, err := j.Runner.Prepare(input)
     req.Position = position
+    req.Active = active
     if err != nil {
         j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
-        j.incError()
+        j.incError(ErrorClassOther)
+        req.Input = input
+        j.Output.ErrorResult(req, ErrorClassOther, err)
         log.Printf("%s", err)
         return
     }
-    resp, err := j.Runner.Execute(&req)
-    if err != nil {
-        if retried {
-            j.incError()
-            log.Printf("%s", err)
-        } else {
-            j.runTask(input, position, true)
+    var resp Response
+    for attempt := 0; ; attempt++ {
+        resp, err = j.execute(&req)
+        if attempt >= j.Config.Retries || !j.shouldRetry(resp, err) {
+            break
         }
+        time.Sleep(j.retryBackoff(attempt))
+    }
+    if err != nil {
+        errclass := ClassifyError(err)
+        j.incError(errclass)
+        j.Output.ErrorResult(req, errclass, err)
+        log.Printf("%s", err)
         return
     }
     if j.SpuriousErrorCounter > 0 {
@@ -287,17 +450,13 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
             }
         }
     }
-    if j.isMatch(resp) {
-        // Re-send request through replay-proxy if needed
+    if j.isMatch(&resp) {
+        // Re-send the exact same request through replay-proxy if needed
         if j.ReplayRunner != nil {
-            replayreq, err := j.ReplayRunner.Prepare(input)
-            replayreq.Position = position
-            if err != nil {
-                j.Output.Error(fmt.Sprintf("Encountered an error while preparing replayproxy request: %s\n", err))
-                j.incError()
+            replayreq := req
+            if _, err := j.ReplayRunner.Execute(&replayreq); err != nil {
+                j.Output.Error(fmt.Sprintf("Encountered an error while replaying request through replayproxy: %s\n", err))
                 log.Printf("%s", err)
-            } else {
-                _, _ = j.ReplayRunner.Execute(&replayreq)
             }
         }
         j.Output.Result(resp)
@@ -311,6 +470,36 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
     return
 }
 
+// shouldRetry returns true if the retry policy allows re-sending the request after the error or response
+func (j *Job) shouldRetry(resp Response, err error) bool {
+    if err != nil {
+        class := ClassifyError(err)
+        for _, c := range j.Config.RetryErrors {
+            if c == class {
+                return true
+            }
+        }
+        return false
+    }
+    for _, s := range j.Config.RetryStatuses {
+        if s.Min <= resp.StatusCode && resp.StatusCode <= s.Max {
+            return true
+        }
+    }
+    return false
+}
+
+// retryBackoff returns the exponential backoff with jitter before the next retry attempt
+func (j *Job) retryBackoff(attempt int) time.Duration {
+    backoff := j.Config.RetryDelay * float64(int(1)<<uint(attempt))
+    if backoff > maxRetryBackoff {
+        backoff = maxRetryBackoff
+    }
+    // Jitter between 50% and 150% of the backoff
+    backoff = backoff/2 + rand.Float64()*backoff
+    return time.Duration(backoff * float64(time.Second))
+}
+
 // handleRecursionJob adds a new recursion job to the job queue if a new directory is found
 func (j *Job) handleRecursionJob(resp Response) {
     if (resp.Request.Url + "/") != resp.GetRedirectLocation(true) {
@@ -330,40 +519,23 @@ func (j *Job) handleRecursionJob(resp Response) {
 
 // CalibrateResponses returns slice of Responses for randomly generated filter autocalibration requests
 func (j *Job) CalibrateResponses() ([]Response, error) {
-    cInputs := make([]string, 0)
     rand.Seed(time.Now().UnixNano())
-    if len(j.Config.AutoCalibrationStrings) < 1 {
-        cInputs = append(cInputs, "admin"+RandomString(16)+"/")
-        cInputs = append(cInputs, ".htaccess"+RandomString(16))
-        cInputs = append(cInputs, RandomString(16)+"/")
-        cInputs = append(cInputs, RandomString(16))
-        cInputs = append(cInputs, RandomString(6)+"`z'z\"${{%{{\\")
-        cInputs = append(cInputs, RandomString(6)+"\\z`z'z\"${{%{{\\")
-    } else {
-        cInputs = append(cInputs, j.Config.AutoCalibrationStrings...)
-    }
-
     results := make([]Response, 0)
-    for _, input := range cInputs {
-        inputs := make(map[string][]byte, 0)
-        for _, v := range j.Config.InputProviders {
-            inputs[v.Keyword] = []byte(input)
-        }
-
+    for _, inputs := range j.calibrationInputs() {
         req, err := j.Runner.Prepare(inputs)
         if err != nil {
             j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
-            j.incError()
+            j.incError(ErrorClassOther)
             log.Printf("%s", err)
             return results, err
         }
-        resp, err := j.Runner.Execute(&req)
+        resp, err := j.execute(&req)
         if err != nil {
             return results, err
         }
 
         // Only calibrate on responses that would be matched otherwise
-        if j.isMatch(resp) {
+        if j.isMatch(&resp) {
             results = append(results, resp)
         }
     }
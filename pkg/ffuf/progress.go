@@ -5,10 +5,13 @@ import (
 )
 
 type Progress struct {
-    StartedAt  time.Time
-    ReqCount   int
-    ReqTotal   int
-    QueuePos   int
-    QueueTotal int
-    ErrorCount int
+    StartedAt   time.Time
+    ReqCount    int
+    ReqTotal    int
+    QueuePos    int
+    QueueTotal  int
+    ErrorCount  int
+    ErrorClass  map[string]int
+    RateLimit   int
+    ThreadLimit int
 }
@@ -0,0 +1,86 @@
+package ffuf
+
+import (
+    "sync"
+    "time"
+)
+
+// RateThrottle is a token bucket limiting the amount of requests per second shared by all the
+// goroutines of a job. With perHost set, every target host gets a bucket of its own.
+type RateThrottle struct {
+    mu      sync.Mutex
+    rate    int
+    perHost bool
+    buckets map[string]*rateBucket
+}
+
+type rateBucket struct {
+    // tokens may go negative, as it holds the reservations made by the waiting goroutines
+    tokens float64
+    last   time.Time
+}
+
+// NewRateThrottle returns a new RateThrottle. A rate of 0 means no limit.
+func NewRateThrottle(conf *Config) *RateThrottle {
+    return &RateThrottle{
+        rate:    conf.Rate,
+        perHost: conf.RatePerHost,
+        buckets: make(map[string]*rateBucket),
+    }
+}
+
+// Wait blocks until a request to host is allowed to be sent
+func (r *RateThrottle) Wait(host string) {
+    r.mu.Lock()
+    if r.rate <= 0 {
+        r.mu.Unlock()
+        return
+    }
+    if !r.perHost {
+        host = ""
+    }
+    b, ok := r.buckets[host]
+    now := time.Now()
+    if !ok {
+        b = &rateBucket{tokens: 1, last: now}
+        r.buckets[host] = b
+    }
+    // Refill, allowing a burst of a single request
+    b.tokens += now.Sub(b.last).Seconds() * float64(r.rate)
+    if b.tokens > 1 {
+        b.tokens = 1
+    }
+    b.last = now
+    b.tokens--
+    var wait time.Duration
+    if b.tokens < 0 {
+        wait = time.Duration(-b.tokens / float64(r.rate) * float64(time.Second))
+    }
+    r.mu.Unlock()
+    if wait > 0 {
+        time.Sleep(wait)
+    }
+}
+
+// SetRate changes the requests per second limit at runtime. A rate of 0 removes the limit.
+func (r *RateThrottle) SetRate(rate int) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if rate < 0 {
+        rate = 0
+    }
+    r.rate = rate
+    // Drop the pending reservations made with the old rate
+    for _, b := range r.buckets {
+        if b.tokens < 0 {
+            b.tokens = 0
+        }
+    }
+}
+
+// Rate returns the current requests per second limit, 0 meaning no limit
+func (r *RateThrottle) Rate() int {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return r.rate
+}
@@ -0,0 +1,77 @@
+package ffuf
+
+import (
+    "context"
+    "sync"
+    "testing"
+    "time"
+)
+
+func newTestRateThrottle(rate int, perHost bool) *RateThrottle {
+    conf := NewConfig(context.Background())
+    conf.Rate = rate
+    conf.RatePerHost = perHost
+    return NewRateThrottle(&conf)
+}
+
+// waitAll runs the waits for the hosts in goroutines of their own, and returns the time they took
+func waitAll(r *RateThrottle, hosts []string, perGoroutine int) time.Duration {
+    var wg sync.WaitGroup
+    start := time.Now()
+    for _, host := range hosts {
+        wg.Add(1)
+        go func(host string) {
+            defer wg.Done()
+            for i := 0; i < perGoroutine; i++ {
+                r.Wait(host)
+            }
+        }(host)
+    }
+    wg.Wait()
+    return time.Since(start)
+}
+
+func TestRateThrottleConcurrent(t *testing.T) {
+    // 40 requests at 100 per second, the first one being sent right away
+    r := newTestRateThrottle(100, false)
+    hosts := []string{"a", "b", "a", "b", "a", "b", "a", "b"}
+    if elapsed := waitAll(r, hosts, 5); elapsed < 380*time.Millisecond {
+        t.Errorf("Expected the goroutines to stay within the rate together, 40 requests took %s", elapsed)
+    }
+    // Every host gets a bucket of its own, allowing 20 requests to each
+    r = newTestRateThrottle(100, true)
+    if elapsed := waitAll(r, hosts, 5); elapsed < 180*time.Millisecond || elapsed >= 380*time.Millisecond {
+        t.Errorf("Expected the hosts to be limited separately, 20 requests per host took %s", elapsed)
+    }
+}
+
+func TestRateThrottleSetRate(t *testing.T) {
+    r := newTestRateThrottle(2, false)
+    r.Wait("")
+    r.SetRate(100)
+    if r.Rate() != 100 {
+        t.Errorf("Expected the rate to be 100, got %d", r.Rate())
+    }
+    // At the old rate these would take 5 seconds
+    if elapsed := waitAll(r, []string{""}, 10); elapsed < 90*time.Millisecond || elapsed > time.Second {
+        t.Errorf("Expected the new rate to be used, 10 requests took %s", elapsed)
+    }
+}
+
+func TestRateThrottleUnlimited(t *testing.T) {
+    r := newTestRateThrottle(0, false)
+    if elapsed := waitAll(r, []string{"a", "b", "c", "d"}, 1000); elapsed > 500*time.Millisecond {
+        t.Errorf("Was not expecting a limit with the rate 0, 4000 requests took %s", elapsed)
+    }
+    r = newTestRateThrottle(1, false)
+    r.Wait("")
+    for _, rate := range []int{0, -1} {
+        r.SetRate(rate)
+        if r.Rate() != 0 {
+            t.Errorf("Expected the rate %d to remove the limit, got %d", rate, r.Rate())
+        }
+        if elapsed := waitAll(r, []string{""}, 100); elapsed > 500*time.Millisecond {
+            t.Errorf("Was not expecting a limit after setting the rate %d, 100 requests took %s", rate, elapsed)
+        }
+    }
+}
@@ -0,0 +1,160 @@
+package ffuf
+
+import (
+    "bytes"
+    "encoding/json"
+    "html"
+    "net/url"
+    "sort"
+    "strings"
+)
+
+// Inputs shorter than this are not looked for, as they would be found almost everywhere
+const MinReflectionLength = 3
+
+// Reflection is an input value found in the response
+type Reflection struct {
+    Keyword  string `json:"keyword"`
+    Encoding string `json:"encoding"`
+    Location string `json:"location"`
+    Context  string `json:"context"`
+}
+
+func (r Reflection) String() string {
+    return r.Keyword + ":" + r.Encoding + ":" + r.Location + ":" + r.Context
+}
+
+type reflectionForm struct {
+    encoding string
+    value    []byte
+}
+
+// FindReflections looks for the input values of the keywords in the response body and headers,
+// raw and URL, HTML or JSON encoded. All of the keywords are checked if none are given.
+func FindReflections(resp *Response, keywords []string) []Reflection {
+    reflections := make([]Reflection, 0)
+    if resp.Request == nil {
+        return reflections
+    }
+    if len(keywords) == 0 {
+        for k := range resp.Request.Input {
+            keywords = append(keywords, k)
+        }
+        sort.Strings(keywords)
+    }
+    isJSON := false
+    for k, v := range resp.Headers {
+        if strings.EqualFold(k, "Content-Type") && len(v) > 0 && strings.Contains(v[0], "json") {
+            isJSON = true
+        }
+    }
+    headers := make([]string, 0, len(resp.Headers))
+    for k := range resp.Headers {
+        headers = append(headers, k)
+    }
+    sort.Strings(headers)
+
+    seen := make(map[Reflection]bool)
+    add := func(r Reflection) {
+        if !seen[r] {
+            seen[r] = true
+            reflections = append(reflections, r)
+        }
+    }
+    for _, keyword := range keywords {
+        value, ok := resp.Request.Input[keyword]
+        if !ok || len(value) < MinReflectionLength {
+            continue
+        }
+        for _, form := range reflectionForms(string(value)) {
+            for _, pos := range indexAll(resp.Data, form.value) {
+                add(Reflection{Keyword: keyword, Encoding: form.encoding, Location: "body", Context: reflectionContext(resp.Data, pos, isJSON)})
+            }
+            for _, name := range headers {
+                for _, hv := range resp.Headers[name] {
+                    if strings.Contains(hv, string(form.value)) {
+                        add(Reflection{Keyword: keyword, Encoding: form.encoding, Location: "header:" + name, Context: "header"})
+                    }
+                }
+            }
+        }
+    }
+    return reflections
+}
+
+// GetReflections returns the reflections of all the keywords. They are looked for the first time
+// they are needed, and kept in the response for the matchers, filters and output that follow.
+func (resp *Response) GetReflections() []Reflection {
+    if resp.Reflections == nil {
+        resp.Reflections = FindReflections(resp, nil)
+    }
+    return resp.Reflections
+}
+
+// reflectionForms returns the distinct encoded forms of the value, the raw value first
+func reflectionForms(value string) []reflectionForm {
+    forms := make([]reflectionForm, 0)
+    add := func(encoding string, encoded string) {
+        for _, f := range forms {
+            if string(f.value) == encoded {
+                return
+            }
+        }
+        forms = append(forms, reflectionForm{encoding: encoding, value: []byte(encoded)})
+    }
+    add("raw", value)
+    add("url", url.QueryEscape(value))
+    add("url", url.PathEscape(value))
+    add("html", html.EscapeString(value))
+    var buf bytes.Buffer
+    enc := json.NewEncoder(&buf)
+    for _, escapeHTML := range []bool{false, true} {
+        buf.Reset()
+        enc.SetEscapeHTML(escapeHTML)
+        if err := enc.Encode(value); err == nil {
+            // Strip the quotes and the newline added by the encoder
+            encoded := strings.TrimSuffix(buf.String(), "\n")
+            add("json", encoded[1:len(encoded)-1])
+        }
+    }
+    return forms
+}
+
+// indexAll returns the positions of the first few occurrences of sep in s
+func indexAll(s []byte, sep []byte) []int {
+    positions := make([]int, 0)
+    offset := 0
+    for len(positions) < 10 {
+        i := bytes.Index(s[offset:], sep)
+        if i == -1 {
+            break
+        }
+        positions = append(positions, offset+i)
+        offset += i + len(sep)
+    }
+    return positions
+}
+
+// reflectionContext tells where in the HTML document the position is: in a comment, a script, a
+// style, a tag (attribute) or in the text. JSON responses have their own context.
+func reflectionContext(body []byte, pos int, isJSON bool) string {
+    if isJSON {
+        return "json"
+    }
+    prefix := bytes.ToLower(body[:pos])
+    if bytes.LastIndex(prefix, []byte("<!--")) > bytes.LastIndex(prefix, []byte("-->")) {
+        return "comment"
+    }
+    lastTagStart := bytes.LastIndexByte(prefix, '<')
+    lastTagEnd := bytes.LastIndexByte(prefix, '>')
+    for _, block := range []string{"script", "style"} {
+        start := bytes.LastIndex(prefix, []byte("<"+block))
+        if start != -1 && start > bytes.LastIndex(prefix, []byte("</"+block)) && lastTagEnd > start {
+            return block
+        }
+    }
+    if lastTagStart > lastTagEnd {
+        return "attribute"
+    }
+    return "text"
+}
@@ -2,14 +2,16 @@ package ffuf
 
 // Request holds the meaningful data that is passed for runner for making the query
 type Request struct {
-    Method   string
-    Host     string
-    Url      string
-    Headers  map[string]string
-    Data     []byte
-    Input    map[string][]byte
-    Position int
-    Raw      string
+    Method     string
+    Host       string
+    Url        string
+    Headers    map[string]string
+    Data       []byte
+    Input      map[string][]byte
+    Position   int
+    Active     string // keywords being iterated in sniper mode, comma separated for a table
+    Raw        string
+    RawRequest []byte // request bytes written as-is to the socket by the raw runner
 }
 
 func NewRequest(conf *Config) Request {
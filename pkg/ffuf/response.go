@@ -1,23 +1,28 @@
 package ffuf
 
 import (
+    "net/http"
     "net/url"
+    "time"
 
     "github.com/valyala/fasthttp"
 )
 
 // Response struct holds the meaningful data returned from request and is meant for passing to filters
 type Response struct {
-    StatusCode    int64
-    Headers       map[string][]string
-    Data          []byte
-    ContentLength int64
-    ContentWords  int64
-    ContentLines  int64
-    Cancelled     bool
-    Request       *Request
-    Raw           string
-    ResultFile    string
+    StatusCode      int64
+    Headers         map[string][]string
+    Data            []byte
+    ContentLength   int64
+    ContentWords    int64
+    ContentLines    int64
+    Cancelled       bool
+    Request         *Request
+    Raw             string
+    ResultFile      string
+    TimeToFirstByte time.Duration
+    Duration        time.Duration
+    Reflections     []Reflection
 }
 
 // GetRedirectLocation returns the redirect location for a 3xx redirect HTTP response
@@ -61,3 +66,19 @@ func NewResponse(httpresp *fasthttp.Response, req *Request) Response {
     resp.ResultFile = ""
     return resp
 }
+
+// NewHTTPResponse creates a Response from a net/http response
+func NewHTTPResponse(httpresp *http.Response, req *Request) Response {
+    var resp Response
+    headers := map[string][]string{}
+    for k, v := range httpresp.Header {
+        headers[k] = v
+    }
+    resp.Request = req
+    resp.StatusCode = int64(httpresp.StatusCode)
+    resp.Headers = headers
+    resp.Cancelled = false
+    resp.Raw = ""
+    resp.ResultFile = ""
+    return resp
+}
@@ -0,0 +1,14 @@
+// +build !windows
+
+package ffuf
+
+import (
+    "os"
+    "syscall"
+)
+
+var (
+    // SIGUSR1 doubles and SIGUSR2 halves the request rate of a running job
+    rateIncreaseSignal os.Signal = syscall.SIGUSR1
+    rateDecreaseSignal os.Signal = syscall.SIGUSR2
+)
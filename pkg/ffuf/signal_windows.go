@@ -0,0 +1,13 @@
+// +build windows
+
+package ffuf
+
+import (
+    "os"
+)
+
+var (
+    // Windows has no user defined signals, the rate cannot be changed at runtime
+    rateIncreaseSignal os.Signal = nil
+    rateDecreaseSignal os.Signal = nil
+)
@@ -0,0 +1,182 @@
+package ffuf
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    // MaxThrottleRequeues is the amount of times a single request is re-sent after a throttling response
+    MaxThrottleRequeues = 10
+    maxThrottleBackoff  = 60 * time.Second
+    maxRetryAfter       = 5 * time.Minute
+)
+
+// AdaptiveThrottle slows the job down when the target pushes back with 429 / 503 responses. It pauses
+// all the requests, halves the concurrency and the request rate, and ramps them back up once the
+// target responds normally again.
+type AdaptiveThrottle struct {
+    mu         sync.Mutex
+    cond       *sync.Cond
+    rate       *RateThrottle
+    maxThreads int
+    maxRate    int
+    threads    int
+    active     int
+    pauseUntil time.Time
+    backoff    time.Duration
+    streak     int
+    limiting   bool
+    rampRate   int
+}
+
+// NewAdaptiveThrottle returns a new AdaptiveThrottle, controlling the rate limit of the job
+func NewAdaptiveThrottle(conf *Config, rate *RateThrottle) *AdaptiveThrottle {
+    a := &AdaptiveThrottle{
+        rate:       rate,
+        maxThreads: conf.Threads,
+        maxRate:    conf.Rate,
+        threads:    conf.Threads,
+    }
+    a.cond = sync.NewCond(&a.mu)
+    return a
+}
+
+// Acquire blocks until the concurrency limit and a possible pause allow sending a request
+func (a *AdaptiveThrottle) Acquire() {
+    a.mu.Lock()
+    for a.active >= a.threads {
+        a.cond.Wait()
+    }
+    a.active++
+    wait := a.pauseUntil.Sub(time.Now())
+    a.mu.Unlock()
+    if wait > 0 {
+        time.Sleep(wait)
+    }
+}
+
+// Release frees the concurrency slot taken by Acquire
+func (a *AdaptiveThrottle) Release() {
+    a.mu.Lock()
+    a.active--
+    a.mu.Unlock()
+    a.cond.Signal()
+}
+
+// Throttled registers a throttling response. retryAfter is the delay requested by the target, or 0.
+// Returns the pause length, and true if the concurrency and rate were cut.
+func (a *AdaptiveThrottle) Throttled(retryAfter time.Duration, measuredRate int) (time.Duration, bool) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    a.streak = 0
+    if retryAfter <= 0 {
+        if a.backoff == 0 {
+            a.backoff = time.Second
+        } else {
+            a.backoff *= 2
+        }
+        if a.backoff > maxThrottleBackoff {
+            a.backoff = maxThrottleBackoff
+        }
+        retryAfter = a.backoff
+    }
+    if retryAfter > maxRetryAfter {
+        retryAfter = maxRetryAfter
+    }
+    now := time.Now()
+    // Cut only once per pause, the requests in flight are likely to get throttled too
+    cut := now.After(a.pauseUntil)
+    if until := now.Add(retryAfter); until.After(a.pauseUntil) {
+        a.pauseUntil = until
+    }
+    if cut {
+        if a.threads > 1 {
+            a.threads = a.threads / 2
+        }
+        current := a.rate.Rate()
+        if current == 0 {
+            // Not limited yet, start from the measured rate. Without a measurement, use the
+            // amount of threads as a floor, so the limit has a real rate to ramp back up to.
+            current = measuredRate
+            if current <= 0 {
+                current = a.maxThreads
+            }
+            if current < 1 {
+                current = 1
+            }
+            a.rampRate = current
+        }
+        if current/2 > 1 {
+            current = current / 2
+        } else {
+            current = 1
+        }
+        a.rate.SetRate(current)
+        a.limiting = true
+    }
+    return retryAfter, cut
+}
+
+// Success registers a response that was not throttled, ramping the concurrency and rate back up
+// after enough of them in a row
+func (a *AdaptiveThrottle) Success() {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if !a.limiting {
+        return
+    }
+    a.streak++
+    if a.streak < a.maxThreads {
+        return
+    }
+    a.streak = 0
+    a.backoff = 0
+    if a.threads < a.maxThreads {
+        a.threads++
+        a.cond.Broadcast()
+    }
+    current := a.rate.Rate()
+    next := current + current/4 + 1
+    if a.maxRate > 0 && next >= a.maxRate {
+        next = a.maxRate
+    } else if a.maxRate == 0 && a.rampRate > 0 && next >= a.rampRate {
+        // Back at the rate measured before getting throttled, remove the limit
+        next = 0
+    }
+    a.rate.SetRate(next)
+    if (next == 0 || next == a.maxRate) && a.threads == a.maxThreads {
+        a.limiting = false
+    }
+}
+
+// Threads returns the current concurrency limit
+func (a *AdaptiveThrottle) Threads() int {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.threads
+}
+
+// IsThrottleResponse returns true if the target asked us to slow down
+func IsThrottleResponse(resp Response) bool {
+    return resp.StatusCode == 429 || resp.StatusCode == 503
+}
+
+// RetryAfter parses the Retry-After header of the response, given either in seconds or as a HTTP date
+func RetryAfter(resp Response) time.Duration {
+    values, ok := resp.Headers["Retry-After"]
+    if !ok || len(values) == 0 {
+        return 0
+    }
+    value := strings.TrimSpace(values[0])
+    if secs, err := strconv.Atoi(value); err == nil {
+        return time.Duration(secs) * time.Second
+    }
+    if t, err := http.ParseTime(value); err == nil {
+        return t.Sub(time.Now())
+    }
+    return 0
+}
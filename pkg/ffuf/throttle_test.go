@@ -0,0 +1,119 @@
+package ffuf
+
+import (
+    "testing"
+    "time"
+)
+
+type throttleStep struct {
+    // throttled registers a throttling response with the measured rate, otherwise successes are
+    // registered
+    throttled    bool
+    measured     int
+    successes    int
+    wantRate     int
+    wantThreads  int
+    wantLimiting bool
+}
+
+func TestAdaptiveThrottleSequence(t *testing.T) {
+    for _, test := range []struct {
+        name    string
+        threads int
+        rate    int
+        steps   []throttleStep
+    }{
+        {
+            name:    "throttled before the rate is measured",
+            threads: 4,
+            steps: []throttleStep{
+                {throttled: true, measured: 0, wantRate: 2, wantThreads: 2, wantLimiting: true},
+                // The limit is kept until the rate is back at the floor of one request per thread
+                {successes: 4, wantRate: 3, wantThreads: 3, wantLimiting: true},
+                {successes: 3, wantRate: 3, wantThreads: 3, wantLimiting: true},
+                {successes: 1, wantRate: 0, wantThreads: 4, wantLimiting: false},
+            },
+        },
+        {
+            name:    "throttled at a measured rate",
+            threads: 2,
+            steps: []throttleStep{
+                {throttled: true, measured: 10, wantRate: 5, wantThreads: 1, wantLimiting: true},
+                {successes: 2, wantRate: 7, wantThreads: 2, wantLimiting: true},
+                {successes: 2, wantRate: 9, wantThreads: 2, wantLimiting: true},
+                {successes: 2, wantRate: 0, wantThreads: 2, wantLimiting: false},
+            },
+        },
+        {
+            name:    "ramps back up to the -rate limit",
+            threads: 2,
+            rate:    8,
+            steps: []throttleStep{
+                {throttled: true, measured: 50, wantRate: 4, wantThreads: 1, wantLimiting: true},
+                {successes: 2, wantRate: 6, wantThreads: 2, wantLimiting: true},
+                {successes: 2, wantRate: 8, wantThreads: 2, wantLimiting: false},
+                {successes: 10, wantRate: 8, wantThreads: 2, wantLimiting: false},
+            },
+        },
+        {
+            name:    "throttled again while limiting",
+            threads: 1,
+            steps: []throttleStep{
+                {throttled: true, measured: 0, wantRate: 1, wantThreads: 1, wantLimiting: true},
+                {throttled: true, measured: 0, wantRate: 1, wantThreads: 1, wantLimiting: true},
+                {successes: 1, wantRate: 0, wantThreads: 1, wantLimiting: false},
+            },
+        },
+        {
+            name:    "successes without throttling",
+            threads: 4,
+            steps: []throttleStep{
+                {successes: 20, wantRate: 0, wantThreads: 4, wantLimiting: false},
+            },
+        },
+    } {
+        conf := Config{Threads: test.threads, Rate: test.rate}
+        a := NewAdaptiveThrottle(&conf, NewRateThrottle(&conf))
+        for i, step := range test.steps {
+            if step.throttled {
+                // Let the previous pause expire, so the throttling response cuts the limits
+                a.pauseUntil = time.Time{}
+                a.Throttled(time.Millisecond, step.measured)
+            }
+            for n := 0; n < step.successes; n++ {
+                a.Success()
+            }
+            if rate := a.rate.Rate(); rate != step.wantRate {
+                t.Errorf("%s, step %d: expected rate %d, got %d", test.name, i, step.wantRate, rate)
+            }
+            if threads := a.Threads(); threads != step.wantThreads {
+                t.Errorf("%s, step %d: expected %d threads, got %d", test.name, i, step.wantThreads, threads)
+            }
+            if a.limiting != step.wantLimiting {
+                t.Errorf("%s, step %d: expected limiting %t, got %t", test.name, i, step.wantLimiting, a.limiting)
+            }
+        }
+    }
+}
+
+func TestAdaptiveThrottlePause(t *testing.T) {
+    conf := Config{Threads: 4}
+    a := NewAdaptiveThrottle(&conf, NewRateThrottle(&conf))
+    for i, test := range []struct {
+        retryAfter time.Duration
+        wantPause  time.Duration
+        wantCut    bool
+    }{
+        // Without Retry-After the pause backs off exponentially
+        {0, time.Second, true},
+        // Throttling responses during the pause do not cut the limits again
+        {0, 2 * time.Second, false},
+        {10 * time.Second, 10 * time.Second, false},
+        {time.Hour, maxRetryAfter, false},
+    } {
+        pause, cut := a.Throttled(test.retryAfter, 0)
+        if pause != test.wantPause || cut != test.wantCut {
+            t.Errorf("Throttle %d: expected pause %s and cut %t, got %s and %t", i, test.wantPause, test.wantCut, pause, cut)
+        }
+    }
+}
@@ -0,0 +1,612 @@
+package filter
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// ExpressionFilter evaluates a boolean expression against the response and its request, eg.
+// status == 200 && len(body) > 500 && headers["Content-Type"] contains "json" && !(input.FUZZ in body)
+//
+// The expression is parsed and type checked when the filter is created, so evaluating it against a
+// response cannot fail.
+type ExpressionFilter struct {
+    root     exprNode
+    valueRaw string
+}
+
+func NewExpressionFilter(value string) (ffuf.FilterProvider, error) {
+    root, err := parseExpression(value)
+    if err != nil {
+        return &ExpressionFilter{}, fmt.Errorf("Expression filter or matcher (-fexpr / -mexpr): %s: %s", err, value)
+    }
+    if root.kind() != kindBool {
+        return &ExpressionFilter{}, fmt.Errorf("Expression filter or matcher (-fexpr / -mexpr): expression has to be a boolean, not a %s: %s", root.kind(), value)
+    }
+    return &ExpressionFilter{root: root, valueRaw: value}, nil
+}
+
+func (f *ExpressionFilter) MarshalJSON() ([]byte, error) {
+    return jsoniter.Marshal(&struct {
+        Value string `json:"value"`
+    }{
+        Value: f.valueRaw,
+    })
+}
+
+func (f *ExpressionFilter) Filter(response *ffuf.Response) (bool, error) {
+    return f.root.eval(response).(bool), nil
+}
+
+func (f *ExpressionFilter) Repr() string {
+    return fmt.Sprintf("Expression: %s", f.valueRaw)
+}
+
+type exprKind int
+
+const (
+    kindBool exprKind = iota
+    kindNumber
+    kindString
+    kindMap
+)
+
+func (k exprKind) String() string {
+    switch k {
+    case kindBool:
+        return "boolean"
+    case kindNumber:
+        return "number"
+    case kindString:
+        return "string"
+    }
+    return "map"
+}
+
+// exprMap is a map of strings, optionally looked up with case-insensitive keys
+type exprMap struct {
+    values map[string]string
+    fold   bool
+}
+
+func (m exprMap) get(key string) (string, bool) {
+    if v, ok := m.values[key]; ok {
+        return v, true
+    }
+    if m.fold {
+        for k, v := range m.values {
+            if strings.EqualFold(k, key) {
+                return v, true
+            }
+        }
+    }
+    return "", false
+}
+
+// exprVariable is a value taken from the response
+type exprVariable struct {
+    kind exprKind
+    get  func(resp *ffuf.Response) interface{}
+}
+
+var exprVariables = map[string]exprVariable{
+    "status": {kindNumber, func(resp *ffuf.Response) interface{} { return float64(resp.StatusCode) }},
+    "length": {kindNumber, func(resp *ffuf.Response) interface{} { return float64(resp.ContentLength) }},
+    "words":  {kindNumber, func(resp *ffuf.Response) interface{} { return float64(resp.ContentWords) }},
+    "lines":  {kindNumber, func(resp *ffuf.Response) interface{} { return float64(resp.ContentLines) }},
+    "duration": {kindNumber, func(resp *ffuf.Response) interface{} {
+        return float64(resp.Duration.Milliseconds())
+    }},
+    "ttfb": {kindNumber, func(resp *ffuf.Response) interface{} {
+        return float64(resp.TimeToFirstByte.Milliseconds())
+    }},
+    "body":     {kindString, func(resp *ffuf.Response) interface{} { return string(resp.Data) }},
+    "redirect": {kindString, func(resp *ffuf.Response) interface{} { return resp.GetRedirectLocation(false) }},
+    "url": {kindString, func(resp *ffuf.Response) interface{} {
+        if resp.Request == nil {
+            return ""
+        }
+        return resp.Request.Url
+    }},
+    "method": {kindString, func(resp *ffuf.Response) interface{} {
+        if resp.Request == nil {
+            return ""
+        }
+        return resp.Request.Method
+    }},
+    "headers": {kindMap, func(resp *ffuf.Response) interface{} {
+        values := make(map[string]string, len(resp.Headers))
+        for k, v := range resp.Headers {
+            values[k] = strings.Join(v, ", ")
+        }
+        return exprMap{values: values, fold: true}
+    }},
+    "input": {kindMap, func(resp *ffuf.Response) interface{} {
+        values := make(map[string]string)
+        if resp.Request != nil {
+            for k, v := range resp.Request.Input {
+                values[k] = string(v)
+            }
+        }
+        return exprMap{values: values}
+    }},
+}
+
+// exprFunctions lists the functions callable from the expressions, with the kind of their argument
+// and their return value
+var exprFunctions = map[string]struct {
+    args   []exprKind
+    result exprKind
+}{
+    "len":   {[]exprKind{kindString}, kindNumber},
+    "lower": {[]exprKind{kindString}, kindString},
+    "upper": {[]exprKind{kindString}, kindString},
+    "trim":  {[]exprKind{kindString}, kindString},
+}
+
+type exprNode interface {
+    kind() exprKind
+    eval(resp *ffuf.Response) interface{}
+}
+
+type exprLiteral struct {
+    k     exprKind
+    value interface{}
+}
+
+func (n *exprLiteral) kind() exprKind                       { return n.k }
+func (n *exprLiteral) eval(resp *ffuf.Response) interface{} { return n.value }
+
+type exprVar struct {
+    v exprVariable
+}
+
+func (n *exprVar) kind() exprKind                       { return n.v.kind }
+func (n *exprVar) eval(resp *ffuf.Response) interface{} { return n.v.get(resp) }
+
+type exprIndex struct {
+    m   exprNode
+    key exprNode
+}
+
+func (n *exprIndex) kind() exprKind { return kindString }
+func (n *exprIndex) eval(resp *ffuf.Response) interface{} {
+    v, _ := n.m.eval(resp).(exprMap).get(n.key.eval(resp).(string))
+    return v
+}
+
+type exprCall struct {
+    name string
+    args []exprNode
+}
+
+func (n *exprCall) kind() exprKind { return exprFunctions[n.name].result }
+func (n *exprCall) eval(resp *ffuf.Response) interface{} {
+    arg := n.args[0].eval(resp).(string)
+    switch n.name {
+    case "len":
+        return float64(len(arg))
+    case "lower":
+        return strings.ToLower(arg)
+    case "upper":
+        return strings.ToUpper(arg)
+    }
+    return strings.TrimSpace(arg)
+}
+
+type exprUnary struct {
+    op      string
+    operand exprNode
+}
+
+func (n *exprUnary) kind() exprKind { return n.operand.kind() }
+func (n *exprUnary) eval(resp *ffuf.Response) interface{} {
+    if n.op == "!" {
+        return !n.operand.eval(resp).(bool)
+    }
+    return -n.operand.eval(resp).(float64)
+}
+
+type exprBinary struct {
+    op          string
+    left, right exprNode
+    re          *regexp.Regexp
+}
+
+func (n *exprBinary) kind() exprKind {
+    if n.op == "+" || n.op == "-" {
+        return n.left.kind()
+    }
+    return kindBool
+}
+
+func (n *exprBinary) eval(resp *ffuf.Response) interface{} {
+    // Short-circuit the boolean operators
+    switch n.op {
+    case "&&":
+        return n.left.eval(resp).(bool) && n.right.eval(resp).(bool)
+    case "||":
+        return n.left.eval(resp).(bool) || n.right.eval(resp).(bool)
+    }
+    l := n.left.eval(resp)
+    r := n.right.eval(resp)
+    switch n.op {
+    case "==":
+        return l == r
+    case "!=":
+        return l != r
+    case "<":
+        return l.(float64) < r.(float64)
+    case "<=":
+        return l.(float64) <= r.(float64)
+    case ">":
+        return l.(float64) > r.(float64)
+    case ">=":
+        return l.(float64) >= r.(float64)
+    case "+":
+        if n.left.kind() == kindString {
+            return l.(string) + r.(string)
+        }
+        return l.(float64) + r.(float64)
+    case "-":
+        return l.(float64) - r.(float64)
+    case "contains":
+        return strings.Contains(l.(string), r.(string))
+    case "in":
+        if m, ok := r.(exprMap); ok {
+            _, found := m.get(l.(string))
+            return found
+        }
+        return strings.Contains(r.(string), l.(string))
+    case "matches":
+        if n.re != nil {
+            return n.re.MatchString(l.(string))
+        }
+        matched, err := regexp.MatchString(r.(string), l.(string))
+        return err == nil && matched
+    }
+    return false
+}
+
+// exprParser is a recursive descent parser. From the lowest precedence: ||, &&, comparisons
+// (== != < <= > >= contains in matches), + -, unary ! -, indexing and function calls.
+type exprParser struct {
+    tokens []string
+    pos    int
+}
+
+func parseExpression(value string) (exprNode, error) {
+    tokens, err := tokenizeExpression(value)
+    if err != nil {
+        return nil, err
+    }
+    p := &exprParser{tokens: tokens}
+    node, err := p.parseOr()
+    if err != nil {
+        return nil, err
+    }
+    if p.pos < len(p.tokens) {
+        return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+    }
+    return node, nil
+}
+
+func (p *exprParser) peek() string {
+    if p.pos < len(p.tokens) {
+        return p.tokens[p.pos]
+    }
+    return ""
+}
+
+func (p *exprParser) next() string {
+    tok := p.peek()
+    p.pos++
+    return tok
+}
+
+func (p *exprParser) expect(tok string) error {
+    if got := p.next(); got != tok {
+        if got == "" {
+            return fmt.Errorf("expected %q, got end of expression", tok)
+        }
+        return fmt.Errorf("expected %q, got %q", tok, got)
+    }
+    return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+    return p.parseBinary([]string{"||"}, p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+    return p.parseBinary([]string{"&&"}, p.parseComparison)
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+    left, err := p.parseAdditive()
+    if err != nil {
+        return nil, err
+    }
+    switch op := p.peek(); op {
+    case "==", "!=", "<", "<=", ">", ">=", "contains", "in", "matches":
+        p.next()
+        right, err := p.parseAdditive()
+        if err != nil {
+            return nil, err
+        }
+        return newExprBinary(op, left, right)
+    }
+    return left, nil
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+    return p.parseBinary([]string{"+", "-"}, p.parseUnary)
+}
+
+// parseBinary parses a left associative chain of the operators
+func (p *exprParser) parseBinary(ops []string, operand func() (exprNode, error)) (exprNode, error) {
+    left, err := operand()
+    if err != nil {
+        return nil, err
+    }
+    for inSlice(p.peek(), ops) {
+        op := p.next()
+        right, err := operand()
+        if err != nil {
+            return nil, err
+        }
+        left, err = newExprBinary(op, left, right)
+        if err != nil {
+            return nil, err
+        }
+    }
+    return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+    op := p.peek()
+    if op != "!" && op != "-" {
+        return p.parsePostfix()
+    }
+    p.next()
+    operand, err := p.parseUnary()
+    if err != nil {
+        return nil, err
+    }
+    if op == "!" && operand.kind() != kindBool {
+        return nil, fmt.Errorf("operator ! needs a boolean, not a %s", operand.kind())
+    }
+    if op == "-" && operand.kind() != kindNumber {
+        return nil, fmt.Errorf("operator - needs a number, not a %s", operand.kind())
+    }
+    return &exprUnary{op: op, operand: operand}, nil
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+    node, err := p.parsePrimary()
+    if err != nil {
+        return nil, err
+    }
+    for p.peek() == "[" || p.peek() == "." {
+        var key exprNode
+        if p.next() == "[" {
+            key, err = p.parseOr()
+            if err != nil {
+                return nil, err
+            }
+            if err := p.expect("]"); err != nil {
+                return nil, err
+            }
+        } else {
+            name := p.next()
+            if !isExprIdent(name) {
+                return nil, fmt.Errorf("expected a name after \".\", got %q", name)
+            }
+            key = &exprLiteral{k: kindString, value: name}
+        }
+        if node.kind() != kindMap {
+            return nil, fmt.Errorf("cannot index a %s", node.kind())
+        }
+        if key.kind() != kindString {
+            return nil, fmt.Errorf("map key has to be a string, not a %s", key.kind())
+        }
+        node = &exprIndex{m: node, key: key}
+    }
+    return node, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+    tok := p.next()
+    switch {
+    case tok == "":
+        return nil, fmt.Errorf("unexpected end of expression")
+    case tok == "(":
+        node, err := p.parseOr()
+        if err != nil {
+            return nil, err
+        }
+        return node, p.expect(")")
+    case tok == "true" || tok == "false":
+        return &exprLiteral{k: kindBool, value: tok == "true"}, nil
+    case tok[0] == '"' || tok[0] == '\'':
+        return &exprLiteral{k: kindString, value: tok[1:]}, nil
+    case tok[0] >= '0' && tok[0] <= '9':
+        num, err := strconv.ParseFloat(tok, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid number %q", tok)
+        }
+        return &exprLiteral{k: kindNumber, value: num}, nil
+    case isExprIdent(tok):
+        if p.peek() == "(" {
+            return p.parseCall(tok)
+        }
+        v, ok := exprVariables[tok]
+        if !ok {
+            return nil, fmt.Errorf("unknown variable %q", tok)
+        }
+        return &exprVar{v: v}, nil
+    }
+    return nil, fmt.Errorf("unexpected %q", tok)
+}
+
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+    fn, ok := exprFunctions[name]
+    if !ok {
+        return nil, fmt.Errorf("unknown function %q", name)
+    }
+    p.next()
+    args := make([]exprNode, 0)
+    for p.peek() != ")" {
+        if len(args) > 0 {
+            if err := p.expect(","); err != nil {
+                return nil, err
+            }
+        }
+        arg, err := p.parseOr()
+        if err != nil {
+            return nil, err
+        }
+        args = append(args, arg)
+    }
+    p.next()
+    if len(args) != len(fn.args) {
+        return nil, fmt.Errorf("function %s takes %d argument(s), got %d", name, len(fn.args), len(args))
+    }
+    for i, arg := range args {
+        if arg.kind() != fn.args[i] {
+            return nil, fmt.Errorf("function %s needs a %s, not a %s", name, fn.args[i], arg.kind())
+        }
+    }
+    return &exprCall{name: name, args: args}, nil
+}
+
+// newExprBinary type checks the operands of a binary operator
+func newExprBinary(op string, left, right exprNode) (exprNode, error) {
+    lk, rk := left.kind(), right.kind()
+    node := &exprBinary{op: op, left: left, right: right}
+    ok := false
+    switch op {
+    case "&&", "||":
+        ok = lk == kindBool && rk == kindBool
+    case "==", "!=":
+        ok = lk == rk && lk != kindMap
+    case "<", "<=", ">", ">=", "-":
+        ok = lk == kindNumber && rk == kindNumber
+    case "+":
+        ok = lk == rk && (lk == kindNumber || lk == kindString)
+    case "contains":
+        ok = lk == kindString && rk == kindString
+    case "in":
+        ok = lk == kindString && (rk == kindString || rk == kindMap)
+    case "matches":
+        ok = lk == kindString && rk == kindString
+        if lit, isLiteral := right.(*exprLiteral); ok && isLiteral {
+            // Compile the constant regular expressions only once
+            re, err := regexp.Compile(lit.value.(string))
+            if err != nil {
+                return nil, fmt.Errorf("invalid regexp %q", lit.value)
+            }
+            node.re = re
+        }
+    }
+    if !ok {
+        return nil, fmt.Errorf("operator %s cannot be used with a %s and a %s", op, lk, rk)
+    }
+    return node, nil
+}
+
+// tokenizeExpression splits the expression to tokens. String tokens keep their opening quote, so
+// they can be told apart from the names.
+func tokenizeExpression(value string) ([]string, error) {
+    tokens := make([]string, 0)
+    runes := []rune(value)
+    for i := 0; i < len(runes); {
+        c := runes[i]
+        switch {
+        case unicode.IsSpace(c):
+            i++
+        case c == '"' || c == '\'':
+            var sb strings.Builder
+            sb.WriteRune(c)
+            j := i + 1
+            for ; j < len(runes) && runes[j] != c; j++ {
+                if runes[j] == '\\' && j+1 < len(runes) {
+                    j++
+                    switch runes[j] {
+                    case 'n':
+                        sb.WriteRune('\n')
+                    case 'r':
+                        sb.WriteRune('\r')
+                    case 't':
+                        sb.WriteRune('\t')
+                    default:
+                        sb.WriteRune(runes[j])
+                    }
+                    continue
+                }
+                sb.WriteRune(runes[j])
+            }
+            if j >= len(runes) {
+                return nil, fmt.Errorf("unterminated string")
+            }
+            tokens = append(tokens, sb.String())
+            i = j + 1
+        case unicode.IsDigit(c):
+            j := i
+            for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+                j++
+            }
+            tokens = append(tokens, string(runes[i:j]))
+            i = j
+        case c == '_' || unicode.IsLetter(c):
+            j := i
+            for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+                j++
+            }
+            tokens = append(tokens, string(runes[i:j]))
+            i = j
+        default:
+            if i+1 < len(runes) {
+                two := string(runes[i : i+2])
+                if inSlice(two, []string{"==", "!=", "<=", ">=", "&&", "||"}) {
+                    tokens = append(tokens, two)
+                    i += 2
+                    continue
+                }
+            }
+            if !strings.ContainsRune("<>!()[].,+-", c) {
+                return nil, fmt.Errorf("unexpected character %q", c)
+            }
+            tokens = append(tokens, string(c))
+            i++
+        }
+    }
+    return tokens, nil
+}
+
+func isExprIdent(tok string) bool {
+    if len(tok) == 0 {
+        return false
+    }
+    for i, c := range tok {
+        if !(c == '_' || unicode.IsLetter(c) || (i > 0 && unicode.IsDigit(c))) {
+            return false
+        }
+    }
+    return !inSlice(tok, []string{"true", "false", "contains", "in", "matches"})
+}
+
+func inSlice(key string, slice []string) bool {
+    for _, v := range slice {
+        if v == key {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,85 @@
+package filter
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestNewExpressionFilter(t *testing.T) {
+    f, _ := NewExpressionFilter(`status == 200 && len(body) > 5`)
+    exprRepr := f.Repr()
+    if strings.Index(exprRepr, "status == 200 && len(body) > 5") == -1 {
+        t.Errorf("Expression filter was expected to have the expression in its representation")
+    }
+}
+
+func TestNewExpressionFilterError(t *testing.T) {
+    for _, value := range []string{
+        "",
+        "status",
+        "status ==",
+        "status == \"200\"",
+        "unknown == 1",
+        "nope(body) > 1",
+        "len(status) > 1",
+        "len(body, body) > 1",
+        "(status == 200",
+        "body matches \"[\"",
+        "headers == 1",
+        "status contains 2",
+        "\"abc",
+        "status == 200 $",
+    } {
+        _, err := NewExpressionFilter(value)
+        if err == nil {
+            t.Errorf("Was expecting an error from errenous input data %q", value)
+        }
+    }
+}
+
+func TestExpressionFiltering(t *testing.T) {
+    resp := ffuf.Response{
+        StatusCode:    200,
+        Headers:       map[string][]string{"Content-Type": {"application/json"}},
+        Data:          []byte(`{"user": "admin", "id": 1}`),
+        ContentLength: 26,
+        ContentWords:  4,
+        ContentLines:  1,
+        Request: &ffuf.Request{
+            Method: "GET",
+            Url:    "http://example.com/api/admin",
+            Input:  map[string][]byte{"FUZZ": []byte("admin"), "W2": []byte("guest")},
+        },
+    }
+    for i, test := range []struct {
+        expr   string
+        output bool
+    }{
+        {`status == 200 && len(body) > 20`, true},
+        {`status == 200 && len(body) > 500`, false},
+        {`headers["content-type"] contains "json"`, true},
+        {`headers["X-Missing"] == ""`, true},
+        {`"Content-Type" in headers && !("X-Missing" in headers)`, true},
+        {`input.FUZZ in body`, true},
+        {`!(input.W2 in body)`, true},
+        {`input["FUZZ"] == "admin"`, true},
+        {`status >= 300 || words == 4`, true},
+        {`status != 200 || (lines > 1 && length < 100)`, false},
+        {`body matches "\"id\": [0-9]+"`, true},
+        {`url matches "/api/" + input.FUZZ + "$"`, true},
+        {`upper(method) == "GET" && lower("ABC") == "abc"`, true},
+        {`-status + 400 == 200`, true},
+    } {
+        f, err := NewExpressionFilter(test.expr)
+        if err != nil {
+            t.Errorf("Expression test %d: unexpected error: %s", i, err)
+            continue
+        }
+        filterReturn, _ := f.Filter(&resp)
+        if filterReturn != test.output {
+            t.Errorf("Expression test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+        }
+    }
+}
@@ -24,23 +24,50 @@ func NewFilterByName(name string, value string) (ffuf.FilterProvider, error) {
     if name == "regexp" {
         return NewRegexpFilter(value)
     }
+    if name == "header" {
+        return NewHeaderFilter(value)
+    }
+    if name == "time" {
+        return NewTimeFilter(value)
+    }
+    if name == "expr" {
+        return NewExpressionFilter(value)
+    }
+    if name == "json" {
+        return NewJSONFilter(value)
+    }
+    if name == "reflection" {
+        return NewReflectionFilter(value)
+    }
+    if name == "similarity" {
+        return NewSimilarityFilter(value)
+    }
     return nil, fmt.Errorf("Could not create filter with name %s", name)
 }
 
-// AddFilter adds a new filter to Config
+// AddFilter appends a new filter to Config
 func AddFilter(conf *ffuf.Config, name string, option string) error {
     newf, err := NewFilterByName(name, option)
     if err == nil {
-        conf.Filters[name] = newf
+        conf.Filters = append(conf.Filters, newf)
     }
     return err
 }
 
-// AddMatcher adds a new matcher to Config
+// AddMatcher appends a new matcher to Config
 func AddMatcher(conf *ffuf.Config, name string, option string) error {
     newf, err := NewFilterByName(name, option)
     if err == nil {
-        conf.Matchers[name] = newf
+        conf.Matchers = append(conf.Matchers, newf)
+    }
+    return err
+}
+
+// AddCalibrationFilter appends a new auto-calibration filter to Config, kept apart from the user defined filters
+func AddCalibrationFilter(conf *ffuf.Config, name string, option string) error {
+    newf, err := NewFilterByName(name, option)
+    if err == nil {
+        conf.CalibrationFilters = append(conf.CalibrationFilters, newf)
     }
     return err
 }
@@ -62,6 +89,13 @@ func CalibrateIfNeeded(j *ffuf.Job) error {
 }
 
 func calibrateFilters(j *ffuf.Job, responses []ffuf.Response) {
+    if j.Config.CalibrationThreshold > 0 {
+        // Filter the responses similar to the calibration responses instead of the exact sizes
+        if value := SimilarityValue(j.Config.CalibrationThreshold, responses); value != "" {
+            AddCalibrationFilter(j.Config, "similarity", value)
+        }
+        return
+    }
     sizeCalib := make([]string, 0)
     wordCalib := make([]string, 0)
     lineCalib := make([]string, 0)
@@ -86,12 +120,19 @@ func calibrateFilters(j *ffuf.Job, responses []ffuf.Response) {
     lineCalib = ffuf.UniqStringSlice(lineCalib)
 
     if len(sizeCalib) > 0 {
-        AddFilter(j.Config, "size", strings.Join(sizeCalib, ","))
+        AddCalibrationFilter(j.Config, "size", strings.Join(sizeCalib, ","))
     }
     if len(wordCalib) > 0 {
-        AddFilter(j.Config, "word", strings.Join(wordCalib, ","))
+        AddCalibrationFilter(j.Config, "word", strings.Join(wordCalib, ","))
     }
     if len(lineCalib) > 0 {
-        AddFilter(j.Config, "line", strings.Join(lineCalib, ","))
+        AddCalibrationFilter(j.Config, "line", strings.Join(lineCalib, ","))
+    }
+    // The JSON error responses often echo the input, so their sizes differ between the requests.
+    // The real responses often share the same envelope, so the JSON filter is only added with -acj.
+    if j.Config.CalibrationJSON {
+        if value := jsonCalibrationValue(responses); value != "" {
+            AddCalibrationFilter(j.Config, "json", value)
+        }
     }
 }
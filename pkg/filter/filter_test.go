@@ -1,7 +1,10 @@
 package filter
 
 import (
+    "context"
     "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
 )
 
 func TestNewFilterByName(t *testing.T) {
@@ -29,6 +32,16 @@ func TestNewFilterByName(t *testing.T) {
     if _, ok := ref.(*RegexpFilter); !ok {
         t.Errorf("Was expecting regexpfilter")
     }
+
+    tf, _ := NewFilterByName("time", ">2000")
+    if _, ok := tf.(*TimeFilter); !ok {
+        t.Errorf("Was expecting timefilter")
+    }
+
+    hf, _ := NewFilterByName("header", "Server: nginx")
+    if _, ok := hf.(*HeaderFilter); !ok {
+        t.Errorf("Was expecting headerfilter")
+    }
 }
 
 func TestNewFilterByNameError(t *testing.T) {
@@ -44,3 +57,20 @@ func TestNewFilterByNameNotFound(t *testing.T) {
         t.Errorf("Was expecing an error with invalid filter name")
     }
 }
+
+func TestAddFilterKeepsDuplicates(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    AddFilter(&conf, "regexp", "first")
+    AddFilter(&conf, "size", "42")
+    AddFilter(&conf, "regexp", "second")
+    AddCalibrationFilter(&conf, "size", "1337")
+    if len(conf.Filters) != 3 {
+        t.Fatalf("Was expecting 3 filters, got %d", len(conf.Filters))
+    }
+    if conf.Filters[0].Repr() != "Regexp: first" || conf.Filters[2].Repr() != "Regexp: second" {
+        t.Errorf("Filters were not kept in the order they were added")
+    }
+    if len(conf.CalibrationFilters) != 1 {
+        t.Errorf("Was expecting the calibration filter to be kept apart from the user filters")
+    }
+}
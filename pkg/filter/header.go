@@ -0,0 +1,95 @@
+package filter
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// HeaderFilter matches a single response header. The value is either "Name: regexp" matching the
+// values of the header, "Name" matching when the header is present or "!Name" when it is absent.
+type HeaderFilter struct {
+    Name     string
+    Value    *regexp.Regexp
+    Absent   bool
+    valueRaw string
+}
+
+func NewHeaderFilter(value string) (ffuf.FilterProvider, error) {
+    parts := strings.SplitN(value, ":", 2)
+    name := strings.TrimSpace(parts[0])
+    absent := strings.HasPrefix(name, "!")
+    if absent {
+        name = strings.TrimSpace(name[1:])
+    }
+    if len(name) == 0 || (absent && len(parts) == 2) {
+        return &HeaderFilter{}, fmt.Errorf("Header filter or matcher (-fh / -mh): invalid value: %s", value)
+    }
+    f := &HeaderFilter{Name: name, Absent: absent, valueRaw: value}
+    if len(parts) == 2 {
+        re, err := regexp.Compile(strings.TrimSpace(parts[1]))
+        if err != nil {
+            return &HeaderFilter{}, fmt.Errorf("Header filter or matcher (-fh / -mh): invalid value: %s", value)
+        }
+        f.Value = re
+    }
+    return f, nil
+}
+
+func (f *HeaderFilter) MarshalJSON() ([]byte, error) {
+    return jsoniter.Marshal(&struct {
+        Value string `json:"value"`
+    }{
+        Value: f.valueRaw,
+    })
+}
+
+func (f *HeaderFilter) Filter(response *ffuf.Response) (bool, error) {
+    values := make([]string, 0)
+    found := false
+    for k, v := range response.Headers {
+        if strings.EqualFold(k, f.Name) {
+            found = true
+            values = append(values, v...)
+        }
+    }
+    if f.Absent {
+        return !found, nil
+    }
+    if f.Value == nil || !found {
+        return found, nil
+    }
+    re := f.Value
+    pattern := f.Value.String()
+    if response.Request != nil {
+        for keyword, inputitem := range response.Request.Input {
+            pattern = strings.Replace(pattern, keyword, regexp.QuoteMeta(string(inputitem)), -1)
+        }
+    }
+    if pattern != f.Value.String() {
+        var err error
+        re, err = regexp.Compile(pattern)
+        if err != nil {
+            return false, nil
+        }
+    }
+    for _, v := range values {
+        if re.MatchString(v) {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+func (f *HeaderFilter) Repr() string {
+    if f.Absent {
+        return fmt.Sprintf("Header absent: %s", f.Name)
+    }
+    if f.Value == nil {
+        return fmt.Sprintf("Header present: %s", f.Name)
+    }
+    return fmt.Sprintf("Header: %s: %s", f.Name, f.Value.String())
+}
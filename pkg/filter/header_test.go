@@ -0,0 +1,58 @@
+package filter
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestNewHeaderFilter(t *testing.T) {
+    f, _ := NewHeaderFilter("Server: ngi(nx|ne)")
+    headerRepr := f.Repr()
+    if strings.Index(headerRepr, "Server: ngi(nx|ne)") == -1 {
+        t.Errorf("Header filter was expected to have the header name and a regexp value")
+    }
+}
+
+func TestNewHeaderFilterError(t *testing.T) {
+    for _, value := range []string{"Server: r((", "", ": value", "!Server: nginx"} {
+        _, err := NewHeaderFilter(value)
+        if err == nil {
+            t.Errorf("Was expecting an error from errenous input data %q", value)
+        }
+    }
+}
+
+func TestHeaderFiltering(t *testing.T) {
+    headers := map[string][]string{
+        "Server":       {"nginx/1.18.0"},
+        "Content-Type": {"text/html"},
+        "Set-Cookie":   {"a=b", "session=1234"},
+    }
+    for i, test := range []struct {
+        value  string
+        output bool
+    }{
+        {"Server: nginx", true},
+        {"server: ^nginx/1\\.18", true},
+        {"Server: apache", false},
+        {"set-cookie: ^session=", true},
+        {"X-Powered-By: .*", false},
+        {"Content-Type", true},
+        {"X-Powered-By", false},
+        {"!X-Powered-By", true},
+        {"!content-type", false},
+    } {
+        f, err := NewHeaderFilter(test.value)
+        if err != nil {
+            t.Errorf("Filter test %d: Unexpected error: %s", i, err)
+            continue
+        }
+        resp := ffuf.Response{Headers: headers}
+        filterReturn, _ := f.Filter(&resp)
+        if filterReturn != test.output {
+            t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+        }
+    }
+}
@@ -0,0 +1,366 @@
+package filter
+
+import (
+    "fmt"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// JSONFilter matches JSON responses by path expressions, eg. "$.error.code == 404",
+// "$.items.length > 0" or "$.user.name =~ /^adm/". A path alone checks that it exists, and
+// multiple conditions can be joined with "&&". Only responses with a JSON Content-Type match.
+type JSONFilter struct {
+    Conditions []jsonCondition
+    valueRaw   string
+}
+
+type jsonCondition struct {
+    path  []jsonStep
+    op    string
+    value interface{}
+    re    *regexp.Regexp
+}
+
+type jsonStep struct {
+    key     string
+    index   int
+    isIndex bool
+}
+
+func NewJSONFilter(value string) (ffuf.FilterProvider, error) {
+    conditions := make([]jsonCondition, 0)
+    for _, cv := range splitJSONConditions(value) {
+        c, err := parseJSONCondition(strings.TrimSpace(cv))
+        if err != nil {
+            return &JSONFilter{}, fmt.Errorf("JSON filter or matcher (-fjson / -mjson): %s: %s", err, value)
+        }
+        conditions = append(conditions, c)
+    }
+    return &JSONFilter{Conditions: conditions, valueRaw: value}, nil
+}
+
+func (f *JSONFilter) MarshalJSON() ([]byte, error) {
+    return jsoniter.Marshal(&struct {
+        Value string `json:"value"`
+    }{
+        Value: f.valueRaw,
+    })
+}
+
+func (f *JSONFilter) Filter(response *ffuf.Response) (bool, error) {
+    if !isJSONResponse(response) {
+        return false, nil
+    }
+    var doc interface{}
+    if err := jsoniter.Unmarshal(response.Data, &doc); err != nil {
+        return false, nil
+    }
+    for _, c := range f.Conditions {
+        if !c.match(doc) {
+            return false, nil
+        }
+    }
+    return true, nil
+}
+
+func (f *JSONFilter) Repr() string {
+    return fmt.Sprintf("JSON: %s", f.valueRaw)
+}
+
+func isJSONResponse(response *ffuf.Response) bool {
+    for k, v := range response.Headers {
+        if strings.EqualFold(k, "Content-Type") && len(v) > 0 && strings.Contains(strings.ToLower(v[0]), "json") {
+            return true
+        }
+    }
+    return false
+}
+
+// match evaluates the condition. A path missing from the document never matches.
+func (c jsonCondition) match(doc interface{}) bool {
+    v, ok := resolveJSONPath(doc, c.path)
+    if !ok {
+        return false
+    }
+    switch c.op {
+    case "":
+        return true
+    case "=~":
+        s, isString := v.(string)
+        return isString && c.re.MatchString(s)
+    case "==":
+        return v == c.value
+    case "!=":
+        return v != c.value
+    }
+    a, aok := v.(float64)
+    b, bok := c.value.(float64)
+    if !aok || !bok {
+        return false
+    }
+    switch c.op {
+    case ">":
+        return a > b
+    case ">=":
+        return a >= b
+    case "<":
+        return a < b
+    }
+    return a <= b
+}
+
+// resolveJSONPath walks the path in the document. The "length" key returns the length of an
+// array, a string or an object, unless the object has a key of that name.
+func resolveJSONPath(doc interface{}, path []jsonStep) (interface{}, bool) {
+    cur := doc
+    for _, step := range path {
+        switch v := cur.(type) {
+        case map[string]interface{}:
+            if step.isIndex {
+                return nil, false
+            }
+            next, ok := v[step.key]
+            if !ok {
+                if step.key == "length" {
+                    next = float64(len(v))
+                } else {
+                    return nil, false
+                }
+            }
+            cur = next
+        case []interface{}:
+            if step.isIndex {
+                if step.index < 0 || step.index >= len(v) {
+                    return nil, false
+                }
+                cur = v[step.index]
+            } else if step.key == "length" {
+                cur = float64(len(v))
+            } else {
+                return nil, false
+            }
+        case string:
+            if step.isIndex || step.key != "length" {
+                return nil, false
+            }
+            cur = float64(len(v))
+        default:
+            return nil, false
+        }
+    }
+    return cur, true
+}
+
+// splitJSONConditions splits the value on "&&" outside of the quoted strings and regexps
+func splitJSONConditions(value string) []string {
+    parts := make([]string, 0)
+    var quote byte
+    start := 0
+    for i := 0; i < len(value); i++ {
+        c := value[i]
+        switch {
+        case quote != 0:
+            if c == '\\' {
+                i++
+            } else if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'' || c == '/':
+            quote = c
+        case c == '&' && i+1 < len(value) && value[i+1] == '&':
+            parts = append(parts, value[start:i])
+            start = i + 2
+            i++
+        }
+    }
+    return append(parts, value[start:])
+}
+
+func parseJSONCondition(value string) (jsonCondition, error) {
+    var c jsonCondition
+    if !strings.HasPrefix(value, "$") {
+        return c, fmt.Errorf("path has to start with $")
+    }
+    i := 1
+    for i < len(value) {
+        if value[i] == '.' {
+            j := i + 1
+            for j < len(value) && isJSONKeyChar(value[j]) {
+                j++
+            }
+            if j == i+1 {
+                return c, fmt.Errorf("expected a key after \".\"")
+            }
+            c.path = append(c.path, jsonStep{key: value[i+1 : j]})
+            i = j
+        } else if value[i] == '[' {
+            end := strings.IndexByte(value[i:], ']')
+            if end == -1 {
+                return c, fmt.Errorf("unterminated [")
+            }
+            inner := value[i+1 : i+end]
+            if index, err := strconv.Atoi(inner); err == nil {
+                c.path = append(c.path, jsonStep{index: index, isIndex: true})
+            } else if key, err := unquoteJSONLiteral(inner); err == nil {
+                c.path = append(c.path, jsonStep{key: key})
+            } else {
+                return c, fmt.Errorf("invalid index %s", inner)
+            }
+            i += end + 1
+        } else {
+            break
+        }
+    }
+    rest := strings.TrimSpace(value[i:])
+    if rest == "" {
+        return c, nil
+    }
+    for _, op := range []string{"==", "!=", ">=", "<=", "=~", ">", "<"} {
+        if strings.HasPrefix(rest, op) {
+            c.op = op
+            break
+        }
+    }
+    if c.op == "" {
+        return c, fmt.Errorf("unexpected %q", rest)
+    }
+    literal := strings.TrimSpace(rest[len(c.op):])
+    if c.op == "=~" {
+        pattern := literal
+        if len(pattern) >= 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
+            pattern = pattern[1 : len(pattern)-1]
+        } else if unquoted, err := unquoteJSONLiteral(pattern); err == nil {
+            pattern = unquoted
+        }
+        re, err := regexp.Compile(pattern)
+        if err != nil {
+            return c, fmt.Errorf("invalid regexp %s", literal)
+        }
+        c.re = re
+        return c, nil
+    }
+    switch literal {
+    case "true":
+        c.value = true
+    case "false":
+        c.value = false
+    case "null":
+        c.value = nil
+    default:
+        if s, err := unquoteJSONLiteral(literal); err == nil {
+            c.value = s
+        } else if f, err := strconv.ParseFloat(literal, 64); err == nil {
+            c.value = f
+        } else {
+            return c, fmt.Errorf("invalid value %q", literal)
+        }
+    }
+    if _, isNumber := c.value.(float64); !isNumber && c.op != "==" && c.op != "!=" {
+        return c, fmt.Errorf("operator %s needs a number", c.op)
+    }
+    return c, nil
+}
+
+func isJSONKeyChar(c byte) bool {
+    return c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func unquoteJSONLiteral(value string) (string, error) {
+    if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+        return value[1 : len(value)-1], nil
+    }
+    if len(value) >= 2 && value[0] == '"' {
+        return strconv.Unquote(value)
+    }
+    return "", fmt.Errorf("not a string")
+}
+
+// jsonCalibrationValue returns the conditions for the JSON leaves having the same value in all of
+// the calibration responses, or an empty string if there are none. Values varying between the
+// responses, like the reflected input, are left out.
+func jsonCalibrationValue(responses []ffuf.Response) string {
+    var common map[string]interface{}
+    documents := 0
+    for _, r := range responses {
+        if !isJSONResponse(&r) {
+            continue
+        }
+        var doc interface{}
+        if err := jsoniter.Unmarshal(r.Data, &doc); err != nil {
+            continue
+        }
+        leaves := make(map[string]interface{})
+        flattenJSON(doc, "$", leaves)
+        if common == nil {
+            common = leaves
+        } else {
+            for path, v := range common {
+                if lv, ok := leaves[path]; !ok || lv != v {
+                    delete(common, path)
+                }
+            }
+        }
+        documents++
+    }
+    // A single response does not tell which of the values vary
+    if documents < 2 || len(common) == 0 {
+        return ""
+    }
+    paths := make([]string, 0, len(common))
+    for path := range common {
+        paths = append(paths, path)
+    }
+    sort.Strings(paths)
+    if len(paths) > 5 {
+        paths = paths[:5]
+    }
+    conditions := make([]string, 0)
+    for _, path := range paths {
+        conditions = append(conditions, path+" == "+formatJSONLiteral(common[path]))
+    }
+    return strings.Join(conditions, " && ")
+}
+
+// flattenJSON collects the scalar leaves of the document, with the lengths of the arrays
+func flattenJSON(v interface{}, path string, leaves map[string]interface{}) {
+    switch val := v.(type) {
+    case map[string]interface{}:
+        for k, child := range val {
+            if k == "length" {
+                continue
+            }
+            key := "." + k
+            for i := 0; i < len(k); i++ {
+                if !isJSONKeyChar(k[i]) {
+                    key = "[" + strconv.Quote(k) + "]"
+                    break
+                }
+            }
+            flattenJSON(child, path+key, leaves)
+        }
+    case []interface{}:
+        leaves[path+".length"] = float64(len(val))
+        for i, child := range val {
+            flattenJSON(child, path+"["+strconv.Itoa(i)+"]", leaves)
+        }
+    default:
+        leaves[path] = val
+    }
+}
+
+func formatJSONLiteral(v interface{}) string {
+    switch val := v.(type) {
+    case string:
+        return strconv.Quote(val)
+    case float64:
+        return strconv.FormatFloat(val, 'f', -1, 64)
+    case bool:
+        return strconv.FormatBool(val)
+    }
+    return "null"
+}
@@ -0,0 +1,128 @@
+package filter
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func jsonResponse(body string) ffuf.Response {
+    return ffuf.Response{
+        Headers: map[string][]string{"Content-Type": {"application/json; charset=utf-8"}},
+        Data:    []byte(body),
+    }
+}
+
+func TestNewJSONFilter(t *testing.T) {
+    f, _ := NewJSONFilter("$.error.code == 404 && $.items.length > 0")
+    jsonRepr := f.Repr()
+    if strings.Index(jsonRepr, "$.error.code == 404 && $.items.length > 0") == -1 {
+        t.Errorf("JSON filter was expected to have the expression in its representation")
+    }
+    if len(f.(*JSONFilter).Conditions) != 2 {
+        t.Errorf("JSON filter was expected to have 2 conditions")
+    }
+}
+
+func TestNewJSONFilterError(t *testing.T) {
+    for _, value := range []string{"", "error.code == 404", "$.", "$.a[", "$.a[x]", "$.a ~ 1", "$.a == ", "$.a == abc", "$.a > \"x\"", "$.a =~ /[/", "$.a == 1 && "} {
+        _, err := NewJSONFilter(value)
+        if err == nil {
+            t.Errorf("Was expecting an error from errenous input data %q", value)
+        }
+    }
+}
+
+func TestJSONFiltering(t *testing.T) {
+    body := `{"error": {"code": 404, "message": "Not found"}, "items": [{"name": "admin"}, {"name": "guest"}], "active": true, "next": null, "a b": "c"}`
+    for i, test := range []struct {
+        value  string
+        output bool
+    }{
+        {"$.error.code == 404", true},
+        {"$.error.code != 404", false},
+        {"$.error.code >= 400 && $.error.code < 500", true},
+        {"$.items.length > 0", true},
+        {"$.items.length > 2", false},
+        {"$.items[1].name == 'guest'", true},
+        {"$.items[2].name", false},
+        {"$.items[0].name =~ /^adm/", true},
+        {"$.error.message =~ \"(?i)not found\"", true},
+        {"$.error.code =~ /404/", false},
+        {"$.error.message.length == 9", true},
+        {"$.active == true && $.next == null", true},
+        {`$["a b"] == "c"`, true},
+        {"$.missing", false},
+        {"$.error", true},
+    } {
+        f, err := NewJSONFilter(test.value)
+        if err != nil {
+            t.Errorf("JSON test %d: unexpected error: %s", i, err)
+            continue
+        }
+        resp := jsonResponse(body)
+        filterReturn, _ := f.Filter(&resp)
+        if filterReturn != test.output {
+            t.Errorf("JSON test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+        }
+    }
+    // Not a JSON response
+    f, _ := NewJSONFilter("$.error.code == 404")
+    resp := ffuf.Response{Headers: map[string][]string{"Content-Type": {"text/html"}}, Data: []byte(body)}
+    if filterReturn, _ := f.Filter(&resp); filterReturn {
+        t.Errorf("JSON filter was not expected to match a text/html response")
+    }
+}
+
+func TestJSONCalibrationValue(t *testing.T) {
+    responses := []ffuf.Response{
+        jsonResponse(`{"error": {"code": 404, "message": "No route for /abcdef"}, "ok": false}`),
+        jsonResponse(`{"error": {"code": 404, "message": "No route for /.htaccessxyz"}, "ok": false}`),
+        {Headers: map[string][]string{"Content-Type": {"text/html"}}, Data: []byte("<html></html>")},
+    }
+    value := jsonCalibrationValue(responses)
+    if value != "$.error.code == 404 && $.ok == false" {
+        t.Errorf("Unexpected calibration value: %s", value)
+    }
+    if jsonCalibrationValue(responses[:1]) != "" {
+        t.Errorf("Was not expecting a calibration value from a single response")
+    }
+    f, err := NewJSONFilter(value)
+    if err != nil {
+        t.Fatalf("Unexpected error: %s", err)
+    }
+    for body, expected := range map[string]bool{
+        `{"error": {"code": 404, "message": "No route for /other"}, "ok": false}`: true,
+        `{"error": null, "ok": true, "data": {}}`:                                 false,
+    } {
+        resp := jsonResponse(body)
+        if filterReturn, _ := f.Filter(&resp); filterReturn != expected {
+            t.Errorf("Calibration filter on %s: was expecting %t", body, expected)
+        }
+    }
+}
+
+func TestJSONCalibrationOptIn(t *testing.T) {
+    // The error and the real responses share the same envelope
+    responses := []ffuf.Response{
+        jsonResponse(`{"apiVersion": "2.1", "success": true, "data": {"message": "No route for /abcdef"}}`),
+        jsonResponse(`{"apiVersion": "2.1", "success": true, "data": {"message": "No route for /.htaccessxyz"}}`),
+    }
+    hit := jsonResponse(`{"apiVersion": "2.1", "success": true, "data": {"users": ["admin", "guest"]}}`)
+    for _, enabled := range []bool{false, true} {
+        conf := ffuf.NewConfig(context.Background())
+        conf.CalibrationJSON = enabled
+        calibrateFilters(&ffuf.Job{Config: &conf}, responses)
+        filtered := false
+        for _, f := range conf.CalibrationFilters {
+            if match, _ := f.Filter(&hit); match {
+                filtered = true
+            }
+        }
+        if filtered != enabled {
+            t.Errorf("With the JSON calibration set to %t, the real response was filtered: %t", enabled, filtered)
+        }
+    }
+}
@@ -0,0 +1,69 @@
+package filter
+
+import (
+    "fmt"
+    "strings"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+var reflectionContexts = []string{"text", "attribute", "script", "style", "comment", "json", "header"}
+
+// ReflectionFilter matches the responses reflecting the input of the keywords, raw or encoded. The
+// value is "all" or a comma separated list of keywords, optionally followed by the contexts to
+// match, eg. "FUZZ:attribute,script".
+type ReflectionFilter struct {
+    Keywords []string
+    Contexts []string
+    valueRaw string
+}
+
+func NewReflectionFilter(value string) (ffuf.FilterProvider, error) {
+    parts := strings.SplitN(value, ":", 2)
+    keywords := make([]string, 0)
+    if parts[0] != "all" {
+        for _, k := range strings.Split(parts[0], ",") {
+            k = strings.TrimSpace(k)
+            if k == "" {
+                return &ReflectionFilter{}, fmt.Errorf("Reflection filter or matcher (-frefl / -mrefl): invalid value: %s", value)
+            }
+            keywords = append(keywords, k)
+        }
+    }
+    contexts := make([]string, 0)
+    if len(parts) == 2 {
+        for _, c := range strings.Split(parts[1], ",") {
+            c = strings.TrimSpace(c)
+            if !inSlice(c, reflectionContexts) {
+                return &ReflectionFilter{}, fmt.Errorf("Reflection filter or matcher (-frefl / -mrefl): invalid context %q, expected one of: %s", c, strings.Join(reflectionContexts, ", "))
+            }
+            contexts = append(contexts, c)
+        }
+    }
+    return &ReflectionFilter{Keywords: keywords, Contexts: contexts, valueRaw: value}, nil
+}
+
+func (f *ReflectionFilter) MarshalJSON() ([]byte, error) {
+    return jsoniter.Marshal(&struct {
+        Value string `json:"value"`
+    }{
+        Value: f.valueRaw,
+    })
+}
+
+func (f *ReflectionFilter) Filter(response *ffuf.Response) (bool, error) {
+    for _, r := range response.GetReflections() {
+        if len(f.Keywords) > 0 && !inSlice(r.Keyword, f.Keywords) {
+            continue
+        }
+        if len(f.Contexts) == 0 || inSlice(r.Context, f.Contexts) {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+func (f *ReflectionFilter) Repr() string {
+    return fmt.Sprintf("Reflection: %s", f.valueRaw)
+}
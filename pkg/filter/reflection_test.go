@@ -0,0 +1,80 @@
+package filter
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestNewReflectionFilter(t *testing.T) {
+    f, _ := NewReflectionFilter("FUZZ,W2:attribute,script")
+    reflRepr := f.Repr()
+    if strings.Index(reflRepr, "FUZZ,W2:attribute,script") == -1 {
+        t.Errorf("Reflection filter was expected to have the keywords and contexts")
+    }
+}
+
+func TestNewReflectionFilterError(t *testing.T) {
+    for _, value := range []string{"", "FUZZ,", "all:nowhere", "FUZZ:text,"} {
+        _, err := NewReflectionFilter(value)
+        if err == nil {
+            t.Errorf("Was expecting an error from errenous input data %q", value)
+        }
+    }
+}
+
+func TestReflectionFiltering(t *testing.T) {
+    input := map[string][]byte{"FUZZ": []byte(`"><svg onload=x>`), "W2": []byte("token")}
+    for i, test := range []struct {
+        value   string
+        body    string
+        headers map[string][]string
+        output  bool
+    }{
+        {"all", `<p>Search: "><svg onload=x></p>`, nil, true},
+        {"FUZZ", `<p>Search: &#34;&gt;&lt;svg onload=x&gt;</p>`, nil, true},
+        {"FUZZ", `<a href="/?q=%22%3E%3Csvg+onload%3Dx%3E">`, nil, true},
+        {"FUZZ", `{"q": "\"><svg onload=x>"}`, map[string][]string{"Content-Type": {"application/json"}}, true},
+        {"FUZZ", `nothing here`, map[string][]string{"X-Echo": {`"><svg onload=x>`}}, true},
+        {"FUZZ", `nothing here, not even a token`, nil, false},
+        {"W2", `nothing here, not even a token`, nil, true},
+        {"FUZZ:script", `<p>"><svg onload=x></p>`, nil, false},
+        {"FUZZ:text", `<p>"><svg onload=x></p>`, nil, true},
+        {"FUZZ:script", `<script>var q = '"><svg onload=x>';</script>`, nil, true},
+        {"W2:attribute", `<input value="token">`, nil, true},
+        {"W2:comment", `<!-- token --><p>text</p>`, nil, true},
+        {"W2:text", `<!-- token --><p>text</p>`, nil, false},
+    } {
+        f, _ := NewReflectionFilter(test.value)
+        resp := ffuf.Response{
+            Data:    []byte(test.body),
+            Headers: test.headers,
+            Request: &ffuf.Request{Input: input},
+        }
+        filterReturn, _ := f.Filter(&resp)
+        if filterReturn != test.output {
+            t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+        }
+    }
+}
+
+func TestReflectionFilteringStored(t *testing.T) {
+    resp := ffuf.Response{
+        Data:    []byte(`<input value="token"><p>admin</p>`),
+        Request: &ffuf.Request{Input: map[string][]byte{"FUZZ": []byte("admin"), "W2": []byte("token")}},
+    }
+    f, _ := NewReflectionFilter("W2:attribute")
+    if filterReturn, _ := f.Filter(&resp); !filterReturn {
+        t.Errorf("Was expecting the W2 reflection to match")
+    }
+    if len(resp.Reflections) != 2 {
+        t.Fatalf("Expected the reflections of both of the keywords to be stored in the response, got %v", resp.Reflections)
+    }
+    // The stored reflections are used by the rules that follow, instead of looking for them again
+    resp.Data = []byte("nothing here")
+    f, _ = NewReflectionFilter("FUZZ:text")
+    if filterReturn, _ := f.Filter(&resp); !filterReturn {
+        t.Errorf("Was expecting the stored FUZZ reflection to match")
+    }
+}
@@ -0,0 +1,189 @@
+package filter
+
+import (
+    "fmt"
+    "hash/fnv"
+    "html"
+    "math/bits"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// SimilarityFilter matches the responses whose normalized content is similar to one of the
+// calibration responses with the same status code. The content is compared using 64 bit simhashes,
+// after removing the reflected input and the numbers and tokens that change on every request.
+type SimilarityFilter struct {
+    Threshold float64
+    Value     []similarityHash
+}
+
+type similarityHash struct {
+    status int64
+    hash   uint64
+}
+
+// NewSimilarityFilter creates the filter from a value of form "threshold:status/hash,status/hash",
+// where the threshold is a percentage and the hashes are hexadecimal simhashes.
+func NewSimilarityFilter(value string) (ffuf.FilterProvider, error) {
+    parts := strings.SplitN(value, ":", 2)
+    if len(parts) != 2 {
+        return &SimilarityFilter{}, fmt.Errorf("Similarity filter: invalid value: %s", value)
+    }
+    threshold, err := strconv.ParseFloat(parts[0], 64)
+    if err != nil || threshold <= 0 || threshold > 100 {
+        return &SimilarityFilter{}, fmt.Errorf("Similarity filter: invalid threshold: %s", parts[0])
+    }
+    hashes := make([]similarityHash, 0)
+    for _, sv := range strings.Split(parts[1], ",") {
+        hv := strings.SplitN(sv, "/", 2)
+        if len(hv) != 2 {
+            return &SimilarityFilter{}, fmt.Errorf("Similarity filter: invalid value: %s", sv)
+        }
+        status, err := strconv.ParseInt(hv[0], 10, 64)
+        if err != nil {
+            return &SimilarityFilter{}, fmt.Errorf("Similarity filter: invalid status code: %s", hv[0])
+        }
+        hash, err := strconv.ParseUint(hv[1], 16, 64)
+        if err != nil {
+            return &SimilarityFilter{}, fmt.Errorf("Similarity filter: invalid hash: %s", hv[1])
+        }
+        hashes = append(hashes, similarityHash{status: status, hash: hash})
+    }
+    return &SimilarityFilter{Threshold: threshold, Value: hashes}, nil
+}
+
+// SimilarityValue returns the value for NewSimilarityFilter matching the responses, skipping the
+// responses without any content to compare
+func SimilarityValue(threshold float64, responses []ffuf.Response) string {
+    values := make([]string, 0)
+    for _, r := range responses {
+        hash, ok := ResponseSimhash(&r)
+        if !ok {
+            continue
+        }
+        values = append(values, fmt.Sprintf("%d/%016x", r.StatusCode, hash))
+    }
+    values = ffuf.UniqStringSlice(values)
+    if len(values) == 0 {
+        return ""
+    }
+    return strconv.FormatFloat(threshold, 'f', -1, 64) + ":" + strings.Join(values, ",")
+}
+
+func (f *SimilarityFilter) MarshalJSON() ([]byte, error) {
+    return jsoniter.Marshal(&struct {
+        Value string `json:"value"`
+    }{
+        Value: f.value(),
+    })
+}
+
+func (f *SimilarityFilter) value() string {
+    values := make([]string, 0)
+    for _, v := range f.Value {
+        values = append(values, fmt.Sprintf("%d/%016x", v.status, v.hash))
+    }
+    return strconv.FormatFloat(f.Threshold, 'f', -1, 64) + ":" + strings.Join(values, ",")
+}
+
+func (f *SimilarityFilter) Filter(response *ffuf.Response) (bool, error) {
+    hash, ok := ResponseSimhash(response)
+    if !ok {
+        return false, nil
+    }
+    for _, v := range f.Value {
+        if v.status != response.StatusCode {
+            continue
+        }
+        if Similarity(hash, v.hash) >= f.Threshold {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+func (f *SimilarityFilter) Repr() string {
+    return fmt.Sprintf("Similarity: >= %s%% to %d calibration response(s)", strconv.FormatFloat(f.Threshold, 'f', -1, 64), len(f.Value))
+}
+
+// Similarity returns the similarity of two simhashes as a percentage
+func Similarity(a, b uint64) float64 {
+    return float64(64-bits.OnesCount64(a^b)) * 100 / 64
+}
+
+// ResponseSimhash returns the simhash of the normalized response content and the redirect
+// location. The boolean is false if there's nothing to hash.
+func ResponseSimhash(response *ffuf.Response) (uint64, bool) {
+    tokens := similarityTokens(response)
+    if len(tokens) == 0 {
+        return 0, false
+    }
+    return simhash(tokens), true
+}
+
+// similarityTokens splits the response to words, after removing the reflected input in its raw,
+// URL and HTML encoded forms. Numbers and long tokens, like timestamps and CSRF tokens, are all
+// replaced with the same placeholder.
+func similarityTokens(response *ffuf.Response) []string {
+    content := string(response.Data) + "\n" + response.GetRedirectLocation(false)
+    if response.Request != nil {
+        reflected := make([]string, 0)
+        for _, v := range response.Request.Input {
+            if len(v) < ffuf.MinReflectionLength {
+                continue
+            }
+            s := string(v)
+            reflected = append(reflected, s, url.QueryEscape(s), url.PathEscape(s), html.EscapeString(s))
+        }
+        // Replace the longest forms first, so the shorter ones do not break them apart
+        sort.Slice(reflected, func(i, j int) bool { return len(reflected[i]) > len(reflected[j]) })
+        for _, r := range reflected {
+            content = strings.ReplaceAll(content, r, " ")
+        }
+    }
+    words := strings.FieldsFunc(content, func(c rune) bool {
+        return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+    })
+    for i, w := range words {
+        if len(w) >= 20 || strings.IndexFunc(w, unicode.IsDigit) != -1 {
+            words[i] = "#"
+        }
+    }
+    return words
+}
+
+// simhash computes a 64 bit simhash of the words and the pairs of adjacent words
+func simhash(words []string) uint64 {
+    var weights [64]int
+    add := func(feature string) {
+        h := fnv.New64a()
+        h.Write([]byte(feature))
+        sum := h.Sum64()
+        for i := 0; i < 64; i++ {
+            if sum&(1<<uint(i)) != 0 {
+                weights[i]++
+            } else {
+                weights[i]--
+            }
+        }
+    }
+    for i, w := range words {
+        add(w)
+        if i > 0 {
+            add(words[i-1] + " " + w)
+        }
+    }
+    var hash uint64
+    for i := 0; i < 64; i++ {
+        if weights[i] > 0 {
+            hash |= 1 << uint(i)
+        }
+    }
+    return hash
+}
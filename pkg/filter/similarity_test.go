@@ -0,0 +1,90 @@
+package filter
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func similarityResponse(status int64, input string, body string) ffuf.Response {
+    return ffuf.Response{
+        StatusCode: status,
+        Data:       []byte(body),
+        Request:    &ffuf.Request{Input: map[string][]byte{"FUZZ": []byte(input)}},
+    }
+}
+
+const similarityPage = `<html><head><title>Not found</title></head><body><h1>Page not found</h1>
+<p>The page /%s could not be found on this server. Please check the address or go back to the
+<a href="/">front page</a>.</p><input type="hidden" name="csrf" value="%s"><footer>Generated at %s</footer></body></html>`
+
+func similarityBody(input, token, timestamp string) string {
+    return strings.NewReplacer("/%s", "/"+input, "\"%s\"", "\""+token+"\"", "at %s", "at "+timestamp).Replace(similarityPage)
+}
+
+func TestNewSimilarityFilter(t *testing.T) {
+    f, err := NewSimilarityFilter("90:404/00000000000000ff,200/0000000000000001")
+    if err != nil {
+        t.Fatalf("Unexpected error: %s", err)
+    }
+    if strings.Index(f.Repr(), "2 calibration") == -1 {
+        t.Errorf("Similarity filter was expected to have 2 values")
+    }
+    if f.(*SimilarityFilter).value() != "90:404/00000000000000ff,200/0000000000000001" {
+        t.Errorf("Similarity filter value was not preserved, got %s", f.(*SimilarityFilter).value())
+    }
+}
+
+func TestNewSimilarityFilterError(t *testing.T) {
+    for _, value := range []string{"", "90", "abc:404/ff", "0:404/ff", "101:404/ff", "90:404", "90:abc/ff", "90:404/xyz"} {
+        _, err := NewSimilarityFilter(value)
+        if err == nil {
+            t.Errorf("Was expecting an error from errenous input data %q", value)
+        }
+    }
+}
+
+func TestSimilarityFiltering(t *testing.T) {
+    calibration := []ffuf.Response{
+        similarityResponse(404, "admin8d1hGk2pLq", similarityBody("admin8d1hGk2pLq", "dG9rZW4tb25lLWFiY2RlZmdo", "2020-01-01 10:00:00")),
+        similarityResponse(404, "x<y>&z", similarityBody("x&lt;y&gt;&amp;z", "c2Vjb25kLXRva2VuLXh5ejEy", "2020-01-01 10:00:01")),
+        similarityResponse(404, "empty", ""),
+    }
+    value := SimilarityValue(90, calibration)
+    if strings.Count(value, "/") != 1 {
+        t.Fatalf("Expected one distinct hash from the calibration responses, got %s", value)
+    }
+    f, err := NewSimilarityFilter(value)
+    if err != nil {
+        t.Fatalf("Unexpected error: %s", err)
+    }
+    for i, test := range []struct {
+        resp   ffuf.Response
+        output bool
+    }{
+        {similarityResponse(404, "backup.zip", similarityBody("backup.zip", "YW5vdGhlci1yYW5kb20tdG9rZW4", "2021-12-31 23:59:59")), true},
+        {similarityResponse(404, "a b", similarityBody("a%20b", "YW5vdGhlci1yYW5kb20tdG9rZW4", "2021-12-31 23:59:59")), true},
+        {similarityResponse(200, "backup.zip", similarityBody("backup.zip", "YW5vdGhlci1yYW5kb20tdG9rZW4", "2021-12-31 23:59:59")), false},
+        {similarityResponse(404, "login", "<html><body><form action=\"/login\">Username <input name=\"user\"> Password <input name=\"pass\"></form></body></html>"), false},
+        {similarityResponse(404, "empty", ""), false},
+    } {
+        filterReturn, _ := f.Filter(&test.resp)
+        if filterReturn != test.output {
+            t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+        }
+    }
+}
+
+func TestSimilarityShortInputs(t *testing.T) {
+    // Short inputs are found almost everywhere, removing them would break the page apart
+    calibration := []ffuf.Response{
+        similarityResponse(404, "e", similarityBody("e", "dG9rZW4tb25lLWFiY2RlZmdo", "2020-01-01 10:00:00")),
+        similarityResponse(404, "o", similarityBody("o", "c2Vjb25kLXRva2VuLXh5ejEy", "2020-01-01 10:00:01")),
+    }
+    f, _ := NewSimilarityFilter(SimilarityValue(90, calibration))
+    resp := similarityResponse(404, "backup.zip", similarityBody("backup.zip", "YW5vdGhlci1yYW5kb20tdG9rZW4", "2021-12-31 23:59:59"))
+    if filterReturn, _ := f.Filter(&resp); !filterReturn {
+        t.Errorf("Expected the response to be filtered with the calibration of the short inputs")
+    }
+}
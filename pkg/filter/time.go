@@ -0,0 +1,85 @@
+package filter
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// TimeFilter matches the time to first byte of the response, in milliseconds. Besides the values and
+// ranges, ">N" and "<N" are accepted.
+type TimeFilter struct {
+    Value []ffuf.ValueRange
+}
+
+func NewTimeFilter(value string) (ffuf.FilterProvider, error) {
+    var intranges []ffuf.ValueRange
+    for _, sv := range strings.Split(value, ",") {
+        sv = strings.TrimSpace(sv)
+        var vr ffuf.ValueRange
+        var err error
+        if strings.HasPrefix(sv, ">") || strings.HasPrefix(sv, "<") {
+            var ms int64
+            ms, err = strconv.ParseInt(strings.TrimSpace(sv[1:]), 10, 64)
+            if sv[0] == '>' {
+                vr = ffuf.ValueRange{Min: ms + 1, Max: math.MaxInt64}
+            } else {
+                vr = ffuf.ValueRange{Min: 0, Max: ms - 1}
+            }
+        } else {
+            vr, err = ffuf.ValueRangeFromString(sv)
+        }
+        if err != nil || vr.Min > vr.Max {
+            return &TimeFilter{}, fmt.Errorf("Time filter or matcher (-ft / -mt): invalid value: %s", sv)
+        }
+        intranges = append(intranges, vr)
+    }
+    return &TimeFilter{Value: intranges}, nil
+}
+
+func (f *TimeFilter) MarshalJSON() ([]byte, error) {
+    return jsoniter.Marshal(&struct {
+        Value string `json:"value"`
+    }{
+        Value: strings.Join(f.values(), ","),
+    })
+}
+
+func (f *TimeFilter) Filter(response *ffuf.Response) (bool, error) {
+    elapsed := response.TimeToFirstByte
+    if elapsed == 0 {
+        // The runner could not tell when the first byte arrived
+        elapsed = response.Duration
+    }
+    ms := elapsed.Milliseconds()
+    for _, iv := range f.Value {
+        if iv.Min <= ms && ms <= iv.Max {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+func (f *TimeFilter) Repr() string {
+    return fmt.Sprintf("Response time: %s ms", strings.Join(f.values(), ","))
+}
+
+func (f *TimeFilter) values() []string {
+    value := make([]string, 0)
+    for _, v := range f.Value {
+        if v.Max == math.MaxInt64 {
+            value = append(value, fmt.Sprintf(">%d", v.Min-1))
+        } else if v.Min == 0 && v.Max > 0 {
+            value = append(value, fmt.Sprintf("<%d", v.Max+1))
+        } else if v.Min == v.Max {
+            value = append(value, strconv.FormatInt(v.Min, 10))
+        } else {
+            value = append(value, fmt.Sprintf("%d-%d", v.Min, v.Max))
+        }
+    }
+    return value
+}
@@ -0,0 +1,48 @@
+package filter
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestNewTimeFilter(t *testing.T) {
+    f, _ := NewTimeFilter(">2000,100-200,<50")
+    timeRepr := f.Repr()
+    if strings.Index(timeRepr, ">2000,100-200,<50") == -1 {
+        t.Errorf("Time filter was expected to have 3 values")
+    }
+}
+
+func TestNewTimeFilterError(t *testing.T) {
+    for _, value := range []string{"invalid", ">", "<abc", "200-100"} {
+        _, err := NewTimeFilter(value)
+        if err == nil {
+            t.Errorf("Was expecting an error from errenous input data %q", value)
+        }
+    }
+}
+
+func TestTimeFiltering(t *testing.T) {
+    f, _ := NewTimeFilter(">2000,100-200,<50")
+    for i, test := range []struct {
+        ttfb     time.Duration
+        duration time.Duration
+        output   bool
+    }{
+        {2500 * time.Millisecond, 2600 * time.Millisecond, true},
+        {2000 * time.Millisecond, 2100 * time.Millisecond, false},
+        {150 * time.Millisecond, 3000 * time.Millisecond, true},
+        {10 * time.Millisecond, 20 * time.Millisecond, true},
+        {50 * time.Millisecond, 60 * time.Millisecond, false},
+        {0, 3000 * time.Millisecond, true},
+    } {
+        resp := ffuf.Response{TimeToFirstByte: test.ttfb, Duration: test.duration}
+        filterReturn, _ := f.Filter(&resp)
+        if filterReturn != test.output {
+            t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+        }
+    }
+}
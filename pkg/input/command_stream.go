@@ -0,0 +1,151 @@
+package input
+
+import (
+    "bufio"
+    "io"
+    "os/exec"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// StreamCommandInput starts the input command once and reads the values from its output, one per
+// line or NUL delimited, until the command exits. The amount of values is not known beforehand,
+// so Total() returns the -input-num hint, or -1 if none was given.
+type StreamCommandInput struct {
+    config   *ffuf.Config
+    keyword  string
+    command  string
+    delim    byte
+    position int
+    cmd      *exec.Cmd
+    stdout   io.ReadCloser
+    reader   *bufio.Reader
+    value    []byte
+    loaded   bool
+    ended    bool
+}
+
+func NewStreamCommandInput(keyword string, value string, conf *ffuf.Config) (*StreamCommandInput, error) {
+    var sc StreamCommandInput
+    sc.keyword = keyword
+    sc.config = conf
+    sc.command = value
+    sc.delim = '\n'
+    if conf.InputStream == "null" {
+        sc.delim = 0
+    }
+    return &sc, nil
+}
+
+// start runs the command, stopping the previous one if it is still running
+func (s *StreamCommandInput) start() error {
+    s.stop()
+    s.ended = false
+    s.loaded = false
+    cmd := exec.Command(SHELL_CMD, SHELL_ARG, s.command)
+    stdout, err := cmd.StdoutPipe()
+    if err != nil {
+        return err
+    }
+    if err := cmd.Start(); err != nil {
+        return err
+    }
+    s.cmd = cmd
+    s.stdout = stdout
+    s.reader = bufio.NewReader(stdout)
+    return nil
+}
+
+// stop kills the command if it is still running and releases it
+func (s *StreamCommandInput) stop() {
+    if s.cmd == nil {
+        return
+    }
+    if !s.ended {
+        s.cmd.Process.Kill()
+    }
+    s.stdout.Close()
+    s.cmd.Wait()
+    s.cmd = nil
+}
+
+// read reads the next value from the command output, returning false once the command has exited
+func (s *StreamCommandInput) read() bool {
+    if s.ended {
+        return false
+    }
+    if s.cmd == nil {
+        if err := s.start(); err != nil {
+            s.ended = true
+            return false
+        }
+    }
+    value, err := s.reader.ReadBytes(s.delim)
+    if len(value) == 0 && err != nil {
+        s.ended = true
+        s.stop()
+        return false
+    }
+    if len(value) > 0 && value[len(value)-1] == s.delim {
+        value = value[:len(value)-1]
+    }
+    if s.delim == '\n' && len(value) > 0 && value[len(value)-1] == '\r' {
+        value = value[:len(value)-1]
+    }
+    s.value = value
+    s.loaded = true
+    return true
+}
+
+// Keyword returns the keyword assigned to this InternalInputProvider
+func (s *StreamCommandInput) Keyword() string {
+    return s.keyword
+}
+
+// Position will return the current position in the command output
+func (s *StreamCommandInput) Position() int {
+    return s.position
+}
+
+// ResetPosition starts the command again if any of its values have been used
+func (s *StreamCommandInput) ResetPosition() {
+    if s.position > 0 || s.ended {
+        s.stop()
+        s.ended = false
+        s.loaded = false
+    }
+    s.position = 0
+}
+
+// IncrementPosition moves to the next value, reading past the current one if it was not used
+func (s *StreamCommandInput) IncrementPosition() {
+    if !s.loaded {
+        s.read()
+    }
+    s.loaded = false
+    s.position += 1
+}
+
+// Next reads the next value ahead, and returns a boolean telling if the command produced one
+func (s *StreamCommandInput) Next() bool {
+    if s.loaded {
+        return true
+    }
+    return s.read()
+}
+
+// Value returns the value at current position
+func (s *StreamCommandInput) Value() []byte {
+    if !s.Next() {
+        return []byte{}
+    }
+    return s.value
+}
+
+// Total returns the -input-num hint of the amount of values, or -1 if it is not known
+func (s *StreamCommandInput) Total() int {
+    if s.config.InputNum > 0 {
+        return s.config.InputNum
+    }
+    return -1
+}
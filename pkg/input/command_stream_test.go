@@ -0,0 +1,102 @@
+// +build !windows
+
+package input
+
+import (
+    "context"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// streamValues reads the values of the input until the command ends
+func streamValues(s *StreamCommandInput) []string {
+    values := make([]string, 0)
+    for s.Next() {
+        values = append(values, string(s.Value()))
+        s.IncrementPosition()
+    }
+    return values
+}
+
+func TestStreamCommandInput(t *testing.T) {
+    for _, test := range []struct {
+        name    string
+        command string
+        stream  string
+        want    []string
+    }{
+        {name: "lines", command: `printf 'one\ntwo\r\n\nthree\n'`, stream: "line", want: []string{"one", "two", "", "three"}},
+        {name: "no final line ending", command: `printf 'one\ntwo'`, stream: "line", want: []string{"one", "two"}},
+        {name: "null delimited", command: `printf 'a b\0c\nd\0e'`, stream: "null", want: []string{"a b", "c\nd", "e"}},
+        {name: "no output", command: `true`, stream: "line", want: []string{}},
+        {name: "exit with an error", command: `printf 'x\n'; exit 3`, stream: "line", want: []string{"x"}},
+        {name: "command not found", command: `ffuf-no-such-command 2>/dev/null`, stream: "line", want: []string{}},
+    } {
+        conf := ffuf.NewConfig(context.Background())
+        conf.InputStream = test.stream
+        s, _ := NewStreamCommandInput("FUZZ", test.command, &conf)
+        got := streamValues(s)
+        if !reflect.DeepEqual(got, test.want) {
+            t.Errorf("%s: expected %q, got %q", test.name, test.want, got)
+        }
+        if s.Position() != len(test.want) {
+            t.Errorf("%s: expected the position %d, got %d", test.name, len(test.want), s.Position())
+        }
+        if s.Next() {
+            t.Errorf("%s: expected no values after the command exited", test.name)
+        }
+    }
+}
+
+func TestStreamCommandInputTotal(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    conf.InputStream = "line"
+    conf.InputNum = 0
+    s, _ := NewStreamCommandInput("FUZZ", `printf 'a\nb\n'`, &conf)
+    if s.Total() != -1 {
+        t.Errorf("Expected an unknown total without -input-num, got %d", s.Total())
+    }
+    conf.InputNum = 10
+    if s.Total() != 10 {
+        t.Errorf("Expected the -input-num hint as the total, got %d", s.Total())
+    }
+    // The hint does not limit the values
+    conf.InputNum = 1
+    if got := streamValues(s); len(got) != 2 {
+        t.Errorf("Expected both of the values with a smaller hint, got %q", got)
+    }
+}
+
+func TestStreamCommandInputReset(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    conf.InputStream = "line"
+    s, _ := NewStreamCommandInput("FUZZ", `printf 'a\nb\nc\n'`, &conf)
+    // Skipping a value without reading it
+    s.IncrementPosition()
+    if string(s.Value()) != "b" || s.Position() != 1 {
+        t.Errorf("Expected b at position 1, got %q at %d", s.Value(), s.Position())
+    }
+    s.ResetPosition()
+    if got := streamValues(s); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+        t.Errorf("Expected the command to start again after a reset, got %q", got)
+    }
+
+    // A command still running is stopped by the reset
+    s, _ = NewStreamCommandInput("FUZZ", `printf 'a\n'; sleep 30; printf 'b\n'`, &conf)
+    start := time.Now()
+    if string(s.Value()) != "a" {
+        t.Fatalf("Expected a, got %q", s.Value())
+    }
+    s.IncrementPosition()
+    s.ResetPosition()
+    if string(s.Value()) != "a" || s.Position() != 0 {
+        t.Errorf("Expected a at position 0 after the reset, got %q at %d", s.Value(), s.Position())
+    }
+    s.stop()
+    if time.Since(start) > 10*time.Second {
+        t.Errorf("The running command was not stopped")
+    }
+}
@@ -0,0 +1,159 @@
+package input
+
+import (
+    "fmt"
+    "io/ioutil"
+    "sort"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// ErrorsInput replays the inputs of the failed requests recorded in a json or ejson output file (-oe)
+type ErrorsInput struct {
+    config   *ffuf.Config
+    data     [][]byte
+    position int
+    keyword  string
+}
+
+type errorsFileResult struct {
+    Input      map[string]jsoniter.RawMessage `json:"input"`
+    ErrorClass string                         `json:"error_class"`
+}
+
+type errorsFile struct {
+    Format  string              `json:"format"`
+    Config  jsoniter.RawMessage `json:"config"`
+    Results []errorsFileResult  `json:"results"`
+}
+
+func NewErrorsInput(keyword string, value string, conf *ffuf.Config) (*ErrorsInput, error) {
+    var ei ErrorsInput
+    ei.keyword = keyword
+    ei.config = conf
+    ei.position = 0
+    inputs, err := readErrorInputs(value)
+    if err != nil {
+        return &ei, err
+    }
+    for _, input := range inputs {
+        ei.data = append(ei.data, input[keyword])
+    }
+    return &ei, nil
+}
+
+// ErrorKeywords returns the keywords of the failed requests recorded in the output file
+func ErrorKeywords(path string) ([]string, error) {
+    inputs, err := readErrorInputs(path)
+    if err != nil {
+        return []string{}, err
+    }
+    seen := make(map[string]bool)
+    keywords := make([]string, 0)
+    for _, input := range inputs {
+        for k := range input {
+            if !seen[k] {
+                seen[k] = true
+                keywords = append(keywords, k)
+            }
+        }
+    }
+    sort.Strings(keywords)
+    return keywords, nil
+}
+
+// readErrorInputs reads the inputs of the failed requests from a json or ejson output file
+func readErrorInputs(path string) ([]map[string][]byte, error) {
+    content, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var out errorsFile
+    if err := jsoniter.Unmarshal(content, &out); err != nil {
+        return nil, fmt.Errorf("Could not parse the output file %s: %s", path, err)
+    }
+    // The json format has the inputs as strings, ejson has them base64 encoded
+    var encoded bool
+    switch out.Format {
+    case "json":
+        encoded = false
+    case "ejson":
+        encoded = true
+    case "":
+        // Written before the format was recorded, only the json format has the config
+        encoded = len(out.Config) == 0
+    default:
+        return nil, fmt.Errorf("Could not parse the output file %s: unsupported format %q", path, out.Format)
+    }
+    inputs := make([]map[string][]byte, 0)
+    for _, r := range out.Results {
+        if r.ErrorClass == "" {
+            continue
+        }
+        input := make(map[string][]byte)
+        for k, v := range r.Input {
+            if encoded {
+                var b []byte
+                if err := jsoniter.Unmarshal(v, &b); err != nil {
+                    return nil, fmt.Errorf("Could not parse the output file %s: %s", path, err)
+                }
+                input[k] = b
+            } else {
+                var s string
+                if err := jsoniter.Unmarshal(v, &s); err != nil {
+                    return nil, fmt.Errorf("Could not parse the output file %s: %s", path, err)
+                }
+                input[k] = []byte(s)
+            }
+        }
+        inputs = append(inputs, input)
+    }
+    return inputs, nil
+}
+
+// Position will return the current position in the input list
+func (e *ErrorsInput) Position() int {
+    return e.position
+}
+
+// ResetPosition resets the position back to beginning of the input list.
+func (e *ErrorsInput) ResetPosition() {
+    e.position = 0
+}
+
+// Keyword returns the keyword assigned to this InternalInputProvider
+func (e *ErrorsInput) Keyword() string {
+    return e.keyword
+}
+
+// Next will increment the cursor position, and return a boolean telling if there's inputs left in the list
+func (e *ErrorsInput) Next() bool {
+    if e.position >= len(e.data) {
+        return false
+    }
+    return true
+}
+
+// IncrementPosition will increment the current position in the inputprovider data slice
+func (e *ErrorsInput) IncrementPosition() {
+    e.position += 1
+}
+
+// Value returns the value from the input list at current cursor position
+func (e *ErrorsInput) Value() []byte {
+    return e.data[e.position]
+}
+
+// Sample returns up to count values from the beginning of the failed inputs
+func (e *ErrorsInput) Sample(count int) [][]byte {
+    if count > len(e.data) {
+        count = len(e.data)
+    }
+    return e.data[:count]
+}
+
+// Total returns the amount of failed inputs
+func (e *ErrorsInput) Total() int {
+    return len(e.data)
+}
@@ -0,0 +1,118 @@
+package input
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestReadErrorInputs(t *testing.T) {
+    dir, err := ioutil.TempDir("", "ffuf-errors")
+    if err != nil {
+        t.Fatalf("Could not create the temp dir: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    for _, test := range []struct {
+        name    string
+        content string
+        want    []map[string][]byte
+        wantErr bool
+    }{
+        {
+            name:    "json",
+            content: `{"format":"json","results":[{"input":{"FUZZ":"dGVzdA=="},"error_class":"timeout"},{"input":{"FUZZ":"ok"},"status":200}],"config":{}}`,
+            want:    []map[string][]byte{{"FUZZ": []byte("dGVzdA==")}},
+        },
+        {
+            name:    "json without the config",
+            content: `{"format":"json","results":[{"input":{"FUZZ":"dGVzdA=="},"error_class":"timeout"}]}`,
+            want:    []map[string][]byte{{"FUZZ": []byte("dGVzdA==")}},
+        },
+        {
+            name:    "ejson",
+            content: `{"format":"ejson","results":[{"input":{"FUZZ":"dGVzdA==","HOST":"aG9zdA=="},"error_class":"connection"},{"input":{"FUZZ":"b2s="}}]}`,
+            want:    []map[string][]byte{{"FUZZ": []byte("test"), "HOST": []byte("host")}},
+        },
+        {
+            name:    "ejson with a config",
+            content: `{"format":"ejson","config":{"url":"http://localhost/"},"results":[{"input":{"FUZZ":"dGVzdA=="},"error_class":"timeout"}]}`,
+            want:    []map[string][]byte{{"FUZZ": []byte("test")}},
+        },
+        {
+            name:    "untagged json",
+            content: `{"results":[{"input":{"FUZZ":"dGVzdA=="},"error_class":"timeout"}],"config":{}}`,
+            want:    []map[string][]byte{{"FUZZ": []byte("dGVzdA==")}},
+        },
+        {
+            name:    "untagged ejson",
+            content: `{"results":[{"input":{"FUZZ":"dGVzdA=="},"error_class":"timeout"}]}`,
+            want:    []map[string][]byte{{"FUZZ": []byte("test")}},
+        },
+        {
+            name:    "ejson with an invalid value",
+            content: `{"format":"ejson","results":[{"input":{"FUZZ":"not base64!"},"error_class":"timeout"}]}`,
+            wantErr: true,
+        },
+        {
+            name:    "unknown format",
+            content: `{"format":"csv","results":[]}`,
+            wantErr: true,
+        },
+        {
+            name:    "not json",
+            content: `FUZZ,url`,
+            wantErr: true,
+        },
+    } {
+        path := filepath.Join(dir, "out.json")
+        if err := ioutil.WriteFile(path, []byte(test.content), 0644); err != nil {
+            t.Fatalf("Could not write the output file: %s", err)
+        }
+        inputs, err := readErrorInputs(path)
+        if test.wantErr {
+            if err == nil {
+                t.Errorf("%s: expected an error", test.name)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.name, err)
+            continue
+        }
+        if !reflect.DeepEqual(inputs, test.want) {
+            t.Errorf("%s: expected %q, got %q", test.name, test.want, inputs)
+        }
+    }
+}
+
+func TestErrorsInput(t *testing.T) {
+    f, err := ioutil.TempFile("", "ffuf-errors")
+    if err != nil {
+        t.Fatalf("Could not create the temp file: %s", err)
+    }
+    defer os.Remove(f.Name())
+    f.WriteString(`{"format":"json","results":[{"input":{"FUZZ":"a","HOST":"x"},"error_class":"timeout"},{"input":{"FUZZ":"b","HOST":"y"},"error_class":"connection"}]}`)
+    f.Close()
+
+    keywords, err := ErrorKeywords(f.Name())
+    if err != nil || !reflect.DeepEqual(keywords, []string{"FUZZ", "HOST"}) {
+        t.Errorf("Expected the keywords FUZZ and HOST, got %v (%v)", keywords, err)
+    }
+    ei, err := NewErrorsInput("HOST", f.Name(), &ffuf.Config{})
+    if err != nil {
+        t.Fatalf("Unexpected error %s", err)
+    }
+    values := make([]string, 0)
+    for ei.Next() {
+        values = append(values, string(ei.Value()))
+        ei.IncrementPosition()
+    }
+    if ei.Total() != 2 || !reflect.DeepEqual(values, []string{"x", "y"}) {
+        t.Errorf("Expected the values x and y, got %v with a total of %d", values, ei.Total())
+    }
+}
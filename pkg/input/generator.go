@@ -0,0 +1,277 @@
+package input
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// GeneratorInput generates its values from the position, so nothing is read or kept in memory
+type GeneratorInput struct {
+    keyword  string
+    position int
+    total    int
+    generate func(index int) []byte
+}
+
+func newGeneratorInput(provider ffuf.InputProviderConfig, conf *ffuf.Config) (*GeneratorInput, error) {
+    switch provider.Name {
+    case "range":
+        return NewRangeInput(provider.Keyword, provider.Value, conf)
+    case "charset":
+        return NewCharsetInput(provider.Keyword, provider.Value, conf)
+    }
+    return NewDateInput(provider.Keyword, provider.Value, conf)
+}
+
+// NewRangeInput generates the numbers of a range, eg. "1-100", "0001-9999" (zero padded to the
+// width of the start), "0-1000,10" (with a step of 10) or "-10--1". A start greater than the end
+// counts down.
+func NewRangeInput(keyword string, value string, conf *ffuf.Config) (*GeneratorInput, error) {
+    gen := &GeneratorInput{keyword: keyword}
+    params := strings.Split(value, ",")
+    // The separator is the first dash after the start, which can have a minus sign of its own
+    sep := -1
+    if len(params[0]) > 1 {
+        if i := strings.Index(params[0][1:], "-"); i != -1 {
+            sep = i + 1
+        }
+    }
+    if len(params) > 2 || sep == -1 {
+        return gen, fmt.Errorf("Invalid range %s, expected start-end or start-end,step", value)
+    }
+    bounds := []string{params[0][:sep], params[0][sep+1:]}
+    start, err := strconv.ParseInt(bounds[0], 10, 64)
+    if err != nil {
+        return gen, fmt.Errorf("Invalid range start: %s", bounds[0])
+    }
+    end, err := strconv.ParseInt(bounds[1], 10, 64)
+    if err != nil {
+        return gen, fmt.Errorf("Invalid range end: %s", bounds[1])
+    }
+    step := int64(1)
+    if len(params) == 2 {
+        step, err = strconv.ParseInt(params[1], 10, 64)
+        if err != nil || step < 1 {
+            return gen, fmt.Errorf("Invalid range step: %s", params[1])
+        }
+    }
+    width := 0
+    if len(bounds[0]) > 1 && bounds[0][0] == '0' {
+        width = len(bounds[0])
+    }
+    direction := int64(1)
+    // The distance between the bounds always fits in an uint64
+    distance := uint64(end) - uint64(start)
+    if start > end {
+        direction = -1
+        distance = uint64(start) - uint64(end)
+    }
+    if distance/uint64(step) >= math.MaxInt32 {
+        return gen, fmt.Errorf("Range %s generates too many values", value)
+    }
+    gen.total = int(distance/uint64(step)) + 1
+    gen.generate = func(index int) []byte {
+        return []byte(fmt.Sprintf("%0*d", width, start+direction*step*int64(index)))
+    }
+    return gen, nil
+}
+
+// NewCharsetInput generates all the strings of a character set between two lengths, shortest
+// first, eg. "a-z0-9,1-4" or "abc,3". The character set can contain ranges like a-z.
+func NewCharsetInput(keyword string, value string, conf *ffuf.Config) (*GeneratorInput, error) {
+    gen := &GeneratorInput{keyword: keyword}
+    i := strings.LastIndex(value, ",")
+    if i < 1 {
+        return gen, fmt.Errorf("Invalid charset %s, expected charset,min-max", value)
+    }
+    chars := expandCharset(value[:i])
+    lengths := strings.SplitN(value[i+1:], "-", 2)
+    min, err := strconv.Atoi(lengths[0])
+    max := min
+    if err == nil && len(lengths) == 2 {
+        max, err = strconv.Atoi(lengths[1])
+    }
+    if err != nil || min < 1 || max < min {
+        return gen, fmt.Errorf("Invalid charset lengths: %s", value[i+1:])
+    }
+    // The amount of strings of every length, to find out the length of a string by its index
+    counts := make([]int, 0)
+    total := 0
+    for l := min; l <= max; l++ {
+        count := math.Pow(float64(len(chars)), float64(l))
+        if float64(total)+count > math.MaxInt32 {
+            return gen, fmt.Errorf("Charset %s generates too many values", value)
+        }
+        counts = append(counts, int(count))
+        total += int(count)
+    }
+    gen.total = total
+    gen.generate = func(index int) []byte {
+        l := min
+        for _, c := range counts {
+            if index < c {
+                break
+            }
+            index -= c
+            l++
+        }
+        s := make([]rune, l)
+        for p := l - 1; p >= 0; p-- {
+            s[p] = chars[index%len(chars)]
+            index /= len(chars)
+        }
+        return []byte(string(s))
+    }
+    return gen, nil
+}
+
+// expandCharset expands the ranges like a-z in the character set and removes the duplicates
+func expandCharset(spec string) []rune {
+    chars := make([]rune, 0)
+    seen := make(map[rune]bool)
+    add := func(c rune) {
+        if !seen[c] {
+            seen[c] = true
+            chars = append(chars, c)
+        }
+    }
+    runes := []rune(spec)
+    for i := 0; i < len(runes); i++ {
+        if i+2 < len(runes) && runes[i+1] == '-' && runes[i] <= runes[i+2] {
+            for c := runes[i]; c <= runes[i+2]; c++ {
+                add(c)
+            }
+            i += 2
+            continue
+        }
+        add(runes[i])
+    }
+    return chars
+}
+
+// Layouts accepted for the bounds of the date input
+var dateLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// NewDateInput generates the dates between two bounds, eg. "2020-01-01,2020-12-31". The dates are
+// formatted using an optional Go time layout (default 2006-01-02) and are one day apart, unless a
+// step like "1h" or "7d" is given: "2020-01-01,2020-01-02,20060102-15,1h".
+func NewDateInput(keyword string, value string, conf *ffuf.Config) (*GeneratorInput, error) {
+    gen := &GeneratorInput{keyword: keyword}
+    params := strings.Split(value, ",")
+    if len(params) < 2 || len(params) > 4 {
+        return gen, fmt.Errorf("Invalid date range %s, expected start,end[,layout[,step]]", value)
+    }
+    start, err := parseDate(params[0])
+    if err != nil {
+        return gen, err
+    }
+    end, err := parseDate(params[1])
+    if err != nil {
+        return gen, err
+    }
+    if end.Before(start) {
+        return gen, fmt.Errorf("Invalid date range %s, the end is before the start", value)
+    }
+    layout := "2006-01-02"
+    if len(params) > 2 && params[2] != "" {
+        layout = params[2]
+    }
+    step := 24 * time.Hour
+    if len(params) > 3 {
+        step, err = parseStep(params[3])
+        if err != nil {
+            return gen, err
+        }
+    }
+    // The duration saturates for the ranges longer than 292 years
+    span := end.Sub(start)
+    if start.Add(span).Before(end) || span/step >= math.MaxInt32 {
+        return gen, fmt.Errorf("Date range %s generates too many values", value)
+    }
+    gen.total = int(span/step) + 1
+    gen.generate = func(index int) []byte {
+        return []byte(start.Add(time.Duration(index) * step).Format(layout))
+    }
+    return gen, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+    for _, layout := range dateLayouts {
+        if t, err := time.Parse(layout, value); err == nil {
+            return t, nil
+        }
+    }
+    return time.Time{}, fmt.Errorf("Invalid date %s, expected YYYY-MM-DD or YYYY-MM-DDTHH:MM:SS", value)
+}
+
+// parseStep parses a Go duration, or an amount of days like "7d"
+func parseStep(value string) (time.Duration, error) {
+    var step time.Duration
+    var err error
+    if strings.HasSuffix(value, "d") {
+        var days int
+        days, err = strconv.Atoi(strings.TrimSuffix(value, "d"))
+        step = time.Duration(days) * 24 * time.Hour
+    } else {
+        step, err = time.ParseDuration(value)
+    }
+    if err != nil || step <= 0 {
+        return 0, fmt.Errorf("Invalid date step: %s", value)
+    }
+    return step, nil
+}
+
+// Position will return the current position in the generated values
+func (g *GeneratorInput) Position() int {
+    return g.position
+}
+
+// ResetPosition resets the position back to the first value
+func (g *GeneratorInput) ResetPosition() {
+    g.position = 0
+}
+
+// Keyword returns the keyword assigned to this InternalInputProvider
+func (g *GeneratorInput) Keyword() string {
+    return g.keyword
+}
+
+// Next will increment the cursor position, and return a boolean telling if there's values left
+func (g *GeneratorInput) Next() bool {
+    if g.position >= g.total {
+        return false
+    }
+    return true
+}
+
+// IncrementPosition will increment the current position in the generated values
+func (g *GeneratorInput) IncrementPosition() {
+    g.position += 1
+}
+
+// Value returns the value at current cursor position
+func (g *GeneratorInput) Value() []byte {
+    return g.generate(g.position)
+}
+
+// Sample returns up to count values from the beginning
+func (g *GeneratorInput) Sample(count int) [][]byte {
+    if count > g.total {
+        count = g.total
+    }
+    values := make([][]byte, 0, count)
+    for i := 0; i < count; i++ {
+        values = append(values, g.generate(i))
+    }
+    return values
+}
+
+// Total returns the amount of generated values
+func (g *GeneratorInput) Total() int {
+    return g.total
+}
@@ -0,0 +1,149 @@
+package input
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestRangeInput(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    for _, test := range []struct {
+        value string
+        want  []string
+        total int
+    }{
+        {value: "1-3", want: []string{"1", "2", "3"}},
+        {value: "3-1", want: []string{"3", "2", "1"}},
+        {value: "0008-0011", want: []string{"0008", "0009", "0010", "0011"}},
+        {value: "0-10,5", want: []string{"0", "5", "10"}},
+        {value: "0-11,5", want: []string{"0", "5", "10"}},
+        {value: "-2-1", want: []string{"-2", "-1", "0", "1"}},
+        {value: "-10--8", want: []string{"-10", "-9", "-8"}},
+        {value: "1--1", want: []string{"1", "0", "-1"}},
+        {value: "7-7", want: []string{"7"}},
+        {value: "0-2147483646", total: 2147483647},
+        {value: "0-9223372036854775807,9223372036854775807", total: 2},
+    } {
+        gen, err := NewRangeInput("FUZZ", test.value, &conf)
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.value, err)
+            continue
+        }
+        if test.want == nil {
+            if gen.Total() != test.total {
+                t.Errorf("%s: expected a total of %d, got %d", test.value, test.total, gen.Total())
+            }
+            continue
+        }
+        got := make([]string, 0)
+        for gen.Next() {
+            got = append(got, string(gen.Value()))
+            gen.IncrementPosition()
+        }
+        if strings.Join(got, ",") != strings.Join(test.want, ",") {
+            t.Errorf("%s: expected %v, got %v", test.value, test.want, got)
+        }
+    }
+}
+
+func TestRangeInputError(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    for _, value := range []string{"", "5", "-5", "1-", "a-5", "1-5,0", "1-5,-1", "1-5,2,3", "0-2147483647", "0-9223372036854775807", "-9223372036854775808-9223372036854775807"} {
+        if _, err := NewRangeInput("FUZZ", value, &conf); err == nil {
+            t.Errorf("Was expecting an error from the range %q", value)
+        }
+    }
+}
+
+func TestCharsetInput(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    for _, test := range []struct {
+        value string
+        want  []string
+        total int
+    }{
+        {value: "ab,1", want: []string{"a", "b"}},
+        {value: "ab,1-2", want: []string{"a", "b", "aa", "ab", "ba", "bb"}},
+        {value: "a-c,2", want: []string{"aa", "ab", "ac", "ba", "bb", "bc", "ca", "cb", "cc"}},
+        {value: "aba,1", want: []string{"a", "b"}},
+        {value: "0-9,3", total: 1000},
+        {value: "0-9,1-9", total: 1111111110},
+    } {
+        gen, err := NewCharsetInput("FUZZ", test.value, &conf)
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.value, err)
+            continue
+        }
+        if test.want == nil {
+            if gen.Total() != test.total {
+                t.Errorf("%s: expected a total of %d, got %d", test.value, test.total, gen.Total())
+            }
+            continue
+        }
+        got := make([]string, 0)
+        for gen.Next() {
+            got = append(got, string(gen.Value()))
+            gen.IncrementPosition()
+        }
+        if strings.Join(got, ",") != strings.Join(test.want, ",") {
+            t.Errorf("%s: expected %v, got %v", test.value, test.want, got)
+        }
+    }
+}
+
+func TestCharsetInputError(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    for _, value := range []string{"", "abc", "abc,", "abc,0", "abc,3-2", "abc,x", "0-9,1-10", "a-z,7"} {
+        if _, err := NewCharsetInput("FUZZ", value, &conf); err == nil {
+            t.Errorf("Was expecting an error from the charset %q", value)
+        }
+    }
+}
+
+func TestDateInput(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    for _, test := range []struct {
+        value string
+        want  []string
+        total int
+    }{
+        {value: "2020-02-27,2020-03-01", want: []string{"2020-02-27", "2020-02-28", "2020-02-29", "2020-03-01"}},
+        {value: "2020-01-01,2020-01-01", want: []string{"2020-01-01"}},
+        {value: "2020-01-01,2020-01-02,20060102-15,6h", want: []string{"20200101-00", "20200101-06", "20200101-12", "20200101-18", "20200102-00"}},
+        {value: "2020-01-01,2020-01-20,,7d", want: []string{"2020-01-01", "2020-01-08", "2020-01-15"}},
+        {value: "2020-01-01T10:00:00,2020-01-01 10:00:02,15:04:05,1s", want: []string{"10:00:00", "10:00:01", "10:00:02"}},
+        {value: "2000-01-01,2050-01-01,,1s", total: 1577923201},
+    } {
+        gen, err := NewDateInput("FUZZ", test.value, &conf)
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.value, err)
+            continue
+        }
+        if test.want == nil {
+            if gen.Total() != test.total {
+                t.Errorf("%s: expected a total of %d, got %d", test.value, test.total, gen.Total())
+            }
+            continue
+        }
+        got := make([]string, 0)
+        for gen.Next() {
+            got = append(got, string(gen.Value()))
+            gen.IncrementPosition()
+        }
+        if strings.Join(got, ",") != strings.Join(test.want, ",") {
+            t.Errorf("%s: expected %v, got %v", test.value, test.want, got)
+        }
+    }
+}
+
+func TestDateInputError(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    for _, value := range []string{"", "2020-01-01", "2020-01-02,2020-01-01", "2020-13-01,2021-01-01", "2020-01-01,tomorrow", "2020-01-01,2020-02-01,,0d", "2020-01-01,2020-02-01,,-1h", "2020-01-01,2020-02-01,,1x", "1900-01-01,2100-01-01,,1s", "1000-01-01,2900-01-01"} {
+        if _, err := NewDateInput("FUZZ", value, &conf); err == nil {
+            t.Errorf("Was expecting an error from the date range %q", value)
+        }
+    }
+}
@@ -2,20 +2,32 @@ package input
 
 import (
     "fmt"
+    "io"
+    "strings"
 
     "github.com/theblackturtle/ffuf/pkg/ffuf"
 )
 
+// sampler is implemented by the InternalInputProviders able to return their values ahead
+type sampler interface {
+    Sample(count int) [][]byte
+}
+
 type MainInputProvider struct {
     Providers   []ffuf.InternalInputProvider
     Config      *ffuf.Config
     position    int
     msbIterator int
+    // The providers of the battering-ram payloads, with the same source only once
+    payloads []ffuf.InternalInputProvider
+    sources  map[string]bool
+    // Index of the active provider in sniper and battering-ram modes
+    active int
 }
 
 func NewInputProvider(conf *ffuf.Config) (ffuf.InputProvider, error) {
     validmode := false
-    for _, mode := range []string{"clusterbomb", "pitchfork"} {
+    for _, mode := range []string{"clusterbomb", "pitchfork", "sniper", "battering-ram"} {
         if conf.InputMode == mode {
             validmode = true
         }
@@ -23,13 +35,63 @@ func NewInputProvider(conf *ffuf.Config) (ffuf.InputProvider, error) {
     if !validmode {
         return &MainInputProvider{}, fmt.Errorf("Input mode (-mode) %s not recognized", conf.InputMode)
     }
-    return &MainInputProvider{Config: conf, msbIterator: 0}, nil
+    return &MainInputProvider{Config: conf, msbIterator: 0, sources: make(map[string]bool)}, nil
 }
 
 func (i *MainInputProvider) AddProvider(provider ffuf.InputProviderConfig) error {
-    if provider.Name == "command" {
+    source := provider.Name + ":" + provider.Value + ":" + provider.Columns
+    if provider.Name == "table" && i.sources[source] {
+        // The table was added with the first of its keywords
+        return nil
+    }
+    err := i.addProvider(provider)
+    if err != nil {
+        // A table failing to load is reported once, not for every keyword
+        i.sources[source] = true
+        return err
+    }
+    if i.Config.InputMode == "clusterbomb" && len(i.Providers) > 1 && i.streaming() {
+        return fmt.Errorf("Streamed input commands (-input-stream) can be combined with other inputs only in pitchfork, sniper and battering-ram modes (-mode)")
+    }
+    // Lists of the same source, like "-w list.txt:A -w list.txt:B", are one battering-ram payload set
+    if !i.sources[source] || provider.Name == "command" {
+        i.sources[source] = true
+        i.payloads = append(i.payloads, i.Providers[len(i.Providers)-1])
+    }
+    return nil
+}
+
+func (i *MainInputProvider) addProvider(provider ffuf.InputProviderConfig) error {
+    if provider.Name == "command" && i.Config.InputStream != "" {
+        newcomm, _ := NewStreamCommandInput(provider.Keyword, provider.Value, i.Config)
+        i.Providers = append(i.Providers, newcomm)
+    } else if provider.Name == "command" {
         newcomm, _ := NewCommandInput(provider.Keyword, provider.Value, i.Config)
         i.Providers = append(i.Providers, newcomm)
+    } else if provider.Name == "errors" {
+        newerr, err := NewErrorsInput(provider.Keyword, provider.Value, i.Config)
+        if err != nil {
+            return err
+        }
+        i.Providers = append(i.Providers, newerr)
+    } else if provider.Name == "table" {
+        newtable, err := NewTableInput(provider.Value, provider.Columns, i.Config)
+        if err != nil {
+            return err
+        }
+        i.Providers = append(i.Providers, newtable)
+    } else if provider.Name == "range" || provider.Name == "charset" || provider.Name == "date" {
+        newgen, err := newGeneratorInput(provider, i.Config)
+        if err != nil {
+            return err
+        }
+        i.Providers = append(i.Providers, newgen)
+    } else if useStream(provider.Value, i.Config) {
+        newwl, err := NewStreamWordlistInput(provider.Keyword, provider.Value, i.Config)
+        if err != nil {
+            return err
+        }
+        i.Providers = append(i.Providers, newwl)
     } else {
         // Default to wordlist
         newwl, err := NewWordlistInput(provider.Keyword, provider.Value, i.Config)
@@ -48,13 +110,57 @@ func (i *MainInputProvider) Position() int {
 
 // Next will increment the cursor position, and return a boolean telling if there's inputs left
 func (i *MainInputProvider) Next() bool {
-    if i.position >= i.Total() {
+    if i.streaming() {
+        if !i.streamNext() {
+            return false
+        }
+    } else if i.position >= i.Total() {
         return false
     }
     i.position++
     return true
 }
 
+// streaming returns true if any of the inputs is a streamed command
+func (i *MainInputProvider) streaming() bool {
+    for _, p := range i.Providers {
+        if _, ok := p.(*StreamCommandInput); ok {
+            return true
+        }
+    }
+    return false
+}
+
+// streamNext tells if there are inputs left when the inputs include streamed commands, as they end
+// when their command exits instead of after a known amount of values
+func (i *MainInputProvider) streamNext() bool {
+    switch i.Config.InputMode {
+    case "sniper":
+        return hasNext(i.Providers[i.active:])
+    case "battering-ram":
+        return hasNext(i.payloads[i.active:])
+    case "pitchfork":
+        // The other inputs loop, so the first streamed command to exit ends the inputs
+        for _, p := range i.Providers {
+            if _, ok := p.(*StreamCommandInput); ok && !p.Next() {
+                return false
+            }
+        }
+        return true
+    }
+    // In clusterbomb mode the streamed command is the only input
+    return i.Providers[0].Next()
+}
+
+func hasNext(providers []ffuf.InternalInputProvider) bool {
+    for _, p := range providers {
+        if p.Next() {
+            return true
+        }
+    }
+    return false
+}
+
 // Value returns a map of inputs for keywords
 func (i *MainInputProvider) Value() map[string][]byte {
     retval := make(map[string][]byte)
@@ -64,9 +170,35 @@ func (i *MainInputProvider) Value() map[string][]byte {
     if i.Config.InputMode == "pitchfork" {
         retval = i.pitchforkValue()
     }
+    if i.Config.InputMode == "sniper" {
+        retval = i.sniperValue()
+    }
+    if i.Config.InputMode == "battering-ram" {
+        retval = i.batteringRamValue()
+    }
     return retval
 }
 
+// Sample returns up to count values from the beginning of the input of a keyword, without moving
+// the position. Inputs that cannot be read ahead, like the command input, return no values.
+func (i *MainInputProvider) Sample(keyword string, count int) [][]byte {
+    for _, p := range i.Providers {
+        if t, ok := p.(*TableInput); ok {
+            if values := t.SampleKeyword(keyword, count); len(values) > 0 {
+                return values
+            }
+            continue
+        }
+        if p.Keyword() != keyword {
+            continue
+        }
+        if s, ok := p.(sampler); ok {
+            return s.Sample(count)
+        }
+    }
+    return [][]byte{}
+}
+
 // Reset resets all the inputproviders and counters
 func (i *MainInputProvider) Reset() {
     for _, p := range i.Providers {
@@ -74,6 +206,36 @@ func (i *MainInputProvider) Reset() {
     }
     i.position = 0
     i.msbIterator = 0
+    i.active = 0
+}
+
+// Close releases the files held by the inputs. They are opened again if the inputs are reset.
+func (i *MainInputProvider) Close() {
+    for _, p := range i.Providers {
+        if c, ok := p.(io.Closer); ok {
+            c.Close()
+        }
+    }
+}
+
+// keywords returns the keywords of the provider, which are many for the tables
+func keywords(p ffuf.InternalInputProvider) []string {
+    if t, ok := p.(*TableInput); ok {
+        return t.Keywords()
+    }
+    return []string{p.Keyword()}
+}
+
+// setValues sets the current value of the provider to its keyword, or the values of the current
+// row to all of the keywords of a table
+func setValues(values map[string][]byte, p ffuf.InternalInputProvider) {
+    if t, ok := p.(*TableInput); ok {
+        for k, v := range t.Values() {
+            values[k] = v
+        }
+        return
+    }
+    values[p.Keyword()] = p.Value()
 }
 
 // pitchforkValue returns a map of keyword:value pairs including all inputs.
@@ -85,12 +247,61 @@ func (i *MainInputProvider) pitchforkValue() map[string][]byte {
             // Loop to beginning if the inputprovider has been exhausted
             p.ResetPosition()
         }
-        values[p.Keyword()] = p.Value()
+        setValues(values, p)
         p.IncrementPosition()
     }
     return values
 }
 
+// sniperValue returns a map of keyword:value pairs including all inputs.
+// This mode will iterate through the wordlists one at a time, while the other keywords keep their
+// default value. The keyword being iterated is returned by Active. Unlike the Burp sniper, every
+// keyword has a wordlist of its own: the same payloads are tried in every position when all of
+// the keywords are given the same wordlist.
+func (i *MainInputProvider) sniperValue() map[string][]byte {
+    values := make(map[string][]byte)
+    for _, p := range i.Providers {
+        for _, k := range keywords(p) {
+            values[k] = []byte(i.Config.InputDefaults[k])
+        }
+    }
+    for i.active < len(i.Providers)-1 && !i.Providers[i.active].Next() {
+        i.active++
+    }
+    p := i.Providers[i.active]
+    setValues(values, p)
+    p.IncrementPosition()
+    return values
+}
+
+// Active returns the keywords of the input the last value was taken from in sniper mode, comma
+// separated for a table. It is empty in the other modes, where all of the inputs are iterated.
+func (i *MainInputProvider) Active() string {
+    if i.Config.InputMode != "sniper" || len(i.Providers) == 0 {
+        return ""
+    }
+    return strings.Join(keywords(i.Providers[i.active]), ",")
+}
+
+// batteringRamValue returns a map of keyword:value pairs including all inputs.
+// This mode will place the same value in all of the keywords, iterating through the distinct
+// wordlists one after another.
+func (i *MainInputProvider) batteringRamValue() map[string][]byte {
+    values := make(map[string][]byte)
+    for i.active < len(i.payloads)-1 && !i.payloads[i.active].Next() {
+        i.active++
+    }
+    p := i.payloads[i.active]
+    value := p.Value()
+    for _, kp := range i.Providers {
+        for _, k := range keywords(kp) {
+            values[k] = value
+        }
+    }
+    p.IncrementPosition()
+    return values
+}
+
 // clusterbombValue returns map of keyword:value pairs including all inputs.
 // this mode will iterate through all possible combinations.
 func (i *MainInputProvider) clusterbombValue() map[string][]byte {
@@ -115,7 +326,7 @@ func (i *MainInputProvider) clusterbombValue() map[string][]byte {
             p.ResetPosition()
             signalNext = true
         }
-        values[p.Keyword()] = p.Value()
+        setValues(values, p)
         if first {
             p.IncrementPosition()
             first = false
@@ -135,8 +346,13 @@ func (i *MainInputProvider) clusterbombIteratorReset() {
     }
 }
 
-// Total returns the amount of input combinations available
+// Total returns the amount of input combinations available, or -1 if it is not known
 func (i *MainInputProvider) Total() int {
+    for _, p := range i.Providers {
+        if p.Total() < 0 {
+            return -1
+        }
+    }
     count := 0
     if i.Config.InputMode == "pitchfork" {
         for _, p := range i.Providers {
@@ -151,5 +367,15 @@ func (i *MainInputProvider) Total() int {
             count = count * p.Total()
         }
     }
+    if i.Config.InputMode == "sniper" {
+        for _, p := range i.Providers {
+            count += p.Total()
+        }
+    }
+    if i.Config.InputMode == "battering-ram" {
+        for _, p := range i.payloads {
+            count += p.Total()
+        }
+    }
     return count
 }
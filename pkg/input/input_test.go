@@ -0,0 +1,118 @@
+package input
+
+import (
+    "context"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "reflect"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// inputValues reads all of the values of the input provider, with the active keywords
+func inputValues(ip ffuf.InputProvider) ([]map[string]string, []string) {
+    values := make([]map[string]string, 0)
+    active := make([]string, 0)
+    for ip.Next() {
+        value := make(map[string]string)
+        for k, v := range ip.Value() {
+            value[k] = string(v)
+        }
+        values = append(values, value)
+        active = append(active, ip.Active())
+    }
+    return values, active
+}
+
+func TestSniperMode(t *testing.T) {
+    conf := ffuf.NewConfig(context.Background())
+    conf.InputMode = "sniper"
+    conf.InputDefaults = map[string]string{"A": "a0", "B": ""}
+    ip, _ := NewInputProvider(&conf)
+    for _, p := range []ffuf.InputProviderConfig{
+        {Name: "range", Value: "1-2", Keyword: "A"},
+        {Name: "range", Value: "5-6", Keyword: "B"},
+    } {
+        if err := ip.AddProvider(p); err != nil {
+            t.Fatalf("Could not add the input %s: %s", p.Keyword, err)
+        }
+    }
+    if ip.Total() != 4 {
+        t.Errorf("Expected a total of 4, got %d", ip.Total())
+    }
+    values, active := inputValues(ip)
+    want := []map[string]string{
+        {"A": "1", "B": ""},
+        {"A": "2", "B": ""},
+        {"A": "a0", "B": "5"},
+        {"A": "a0", "B": "6"},
+    }
+    if !reflect.DeepEqual(values, want) {
+        t.Errorf("Expected the values %v, got %v", want, values)
+    }
+    if !reflect.DeepEqual(active, []string{"A", "A", "B", "B"}) {
+        t.Errorf("Expected the active keywords A, A, B, B, got %v", active)
+    }
+}
+
+func TestSniperModeSameWordlist(t *testing.T) {
+    // The Burp sniper: one payload set tried in every position in turn
+    f, err := ioutil.TempFile("", "ffuf-sniper")
+    if err != nil {
+        t.Fatalf("Could not create the temp file: %s", err)
+    }
+    defer os.Remove(f.Name())
+    f.WriteString("x\ny\n")
+    f.Close()
+    conf := ffuf.NewConfig(context.Background())
+    conf.InputMode = "sniper"
+    conf.InputDefaults = map[string]string{"P1": "admin", "P2": "secret"}
+    ip, _ := NewInputProvider(&conf)
+    for _, keyword := range []string{"P1", "P2"} {
+        if err := ip.AddProvider(ffuf.InputProviderConfig{Name: "wordlist", Value: f.Name(), Keyword: keyword}); err != nil {
+            t.Fatalf("Could not add the input %s: %s", keyword, err)
+        }
+    }
+    if ip.Total() != 4 {
+        t.Errorf("Expected a total of 4, got %d", ip.Total())
+    }
+    values, active := inputValues(ip)
+    want := []map[string]string{
+        {"P1": "x", "P2": "secret"},
+        {"P1": "y", "P2": "secret"},
+        {"P1": "admin", "P2": "x"},
+        {"P1": "admin", "P2": "y"},
+    }
+    if !reflect.DeepEqual(values, want) {
+        t.Errorf("Expected the values %v, got %v", want, values)
+    }
+    if !reflect.DeepEqual(active, []string{"P1", "P1", "P2", "P2"}) {
+        t.Errorf("Expected the active keywords P1, P1, P2, P2, got %v", active)
+    }
+}
+
+func TestActiveOtherModes(t *testing.T) {
+    for _, mode := range []string{"clusterbomb", "pitchfork", "battering-ram"} {
+        conf := ffuf.NewConfig(context.Background())
+        conf.InputMode = mode
+        ip, _ := NewInputProvider(&conf)
+        ip.AddProvider(ffuf.InputProviderConfig{Name: "range", Value: "1-2", Keyword: "A"})
+        ip.AddProvider(ffuf.InputProviderConfig{Name: "range", Value: "5-6", Keyword: "B"})
+        values, active := inputValues(ip)
+        if len(values) == 0 {
+            t.Errorf("%s: expected values", mode)
+        }
+        for _, a := range active {
+            if a != "" {
+                t.Errorf("%s: expected no active keyword, got %s", mode, a)
+            }
+        }
+        for _, v := range values {
+            if len(v) != 2 {
+                t.Errorf("%s: expected only the keywords in the values, got %s", mode, fmt.Sprint(v))
+            }
+        }
+    }
+}
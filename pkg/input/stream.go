@@ -0,0 +1,274 @@
+package input
+
+import (
+    "bufio"
+    "io"
+    "os"
+    "regexp"
+    "sort"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// Wordlists larger than this are streamed from the disk even without -stream
+const streamThreshold = 128 * 1024 * 1024
+
+// Amount of lines between the offsets kept in the index
+const streamCheckpointInterval = 1024
+
+var extRegexp = regexp.MustCompile(`(?i)%ext%`)
+
+// StreamWordlistInput reads the wordlist from the disk as the values are needed, instead of holding
+// it in memory. The file is indexed once to count the values, keeping the offset of every
+// streamCheckpointInterval:th line, and the extensions and comments are handled in Value(). The
+// file is closed once the last value has been read, and opened again if the input is reset.
+type StreamWordlistInput struct {
+    config      *ffuf.Config
+    keyword     string
+    path        string
+    file        *os.File
+    reader      *bufio.Reader
+    position    int
+    total       int
+    checkpoints []streamCheckpoint
+    loaded      bool
+    line        []byte
+    lineStart   int
+    lineCount   int
+    offset      int64
+}
+
+// streamCheckpoint is the file offset of a line and the index of its first value
+type streamCheckpoint struct {
+    offset int64
+    index  int
+}
+
+func NewStreamWordlistInput(keyword string, value string, conf *ffuf.Config) (*StreamWordlistInput, error) {
+    var sw StreamWordlistInput
+    sw.keyword = keyword
+    sw.config = conf
+    sw.path = value
+    sw.position = 0
+    file, err := os.Open(value)
+    if err != nil {
+        return &sw, err
+    }
+    sw.file = file
+    sw.reader = bufio.NewReaderSize(file, 1024*1024)
+    err = sw.index()
+    if err != nil {
+        sw.Close()
+    }
+    return &sw, err
+}
+
+// useStream returns true if the wordlist should be streamed from the disk
+func useStream(value string, conf *ffuf.Config) bool {
+    if value == "-" {
+        // stdin cannot be read twice
+        return false
+    }
+    if conf.StreamWordlists {
+        return true
+    }
+    fi, err := os.Stat(value)
+    return err == nil && fi.Size() > streamThreshold
+}
+
+// index reads through the file counting the values and keeping the checkpoints
+func (s *StreamWordlistInput) index() error {
+    var offset int64
+    lines := 0
+    for {
+        raw, n, err := readLine(s.reader)
+        if n == 0 && err == io.EOF {
+            break
+        }
+        if err != nil && err != io.EOF {
+            return err
+        }
+        if lines%streamCheckpointInterval == 0 {
+            s.checkpoints = append(s.checkpoints, streamCheckpoint{offset: offset, index: s.total})
+        }
+        s.total += s.valueCount(raw)
+        offset += int64(n)
+        lines++
+    }
+    return nil
+}
+
+// readLine reads a line without the line ending, returning the amount of bytes consumed
+func readLine(reader *bufio.Reader) ([]byte, int, error) {
+    line, err := reader.ReadBytes('\n')
+    n := len(line)
+    if len(line) > 0 && line[len(line)-1] == '\n' {
+        line = line[:len(line)-1]
+        if len(line) > 0 && line[len(line)-1] == '\r' {
+            line = line[:len(line)-1]
+        }
+    }
+    return line, n, err
+}
+
+func (s *StreamWordlistInput) dirSearch() bool {
+    return s.config.DirSearchCompat && len(s.config.Extensions) > 0
+}
+
+// valueCount returns the amount of values a line of the wordlist produces, in the same way
+// WordlistInput does
+func (s *StreamWordlistInput) valueCount(raw []byte) int {
+    if s.dirSearch() && extRegexp.Match(raw) {
+        return len(s.config.Extensions)
+    }
+    if s.config.IgnoreWordlistComments {
+        if _, ok := stripComments(string(raw)); !ok {
+            return 0
+        }
+    }
+    if !s.dirSearch() && s.keyword == "FUZZ" {
+        return 1 + len(s.config.Extensions)
+    }
+    return 1
+}
+
+// lineValue returns the nth value produced by a line
+func (s *StreamWordlistInput) lineValue(raw []byte, n int) []byte {
+    if s.dirSearch() && extRegexp.Match(raw) {
+        return extRegexp.ReplaceAll(raw, []byte(s.config.Extensions[n]))
+    }
+    text := string(raw)
+    if s.config.IgnoreWordlistComments {
+        text, _ = stripComments(text)
+    }
+    if n > 0 {
+        text += s.config.Extensions[n-1]
+    }
+    return []byte(text)
+}
+
+// seek moves the reader to the last checkpoint before the value index
+func (s *StreamWordlistInput) seek(index int) error {
+    i := sort.Search(len(s.checkpoints), func(i int) bool { return s.checkpoints[i].index > index }) - 1
+    if i < 0 {
+        i = 0
+    }
+    cp := s.checkpoints[i]
+    if s.file == nil {
+        file, err := os.Open(s.path)
+        if err != nil {
+            return err
+        }
+        s.file = file
+    }
+    if _, err := s.file.Seek(cp.offset, io.SeekStart); err != nil {
+        return err
+    }
+    s.reader.Reset(s.file)
+    s.offset = cp.offset
+    s.lineStart = cp.index
+    s.lineCount = 0
+    s.line = nil
+    s.loaded = true
+    return nil
+}
+
+// advance reads the next line of the file
+func (s *StreamWordlistInput) advance() bool {
+    raw, n, err := readLine(s.reader)
+    if n == 0 && err != nil {
+        return false
+    }
+    s.offset += int64(n)
+    s.lineStart += s.lineCount
+    s.line = append(s.line[:0], raw...)
+    s.lineCount = s.valueCount(s.line)
+    return true
+}
+
+// valueAt returns the value at the index, reading forward from the current line when possible
+func (s *StreamWordlistInput) valueAt(index int) []byte {
+    if len(s.checkpoints) == 0 {
+        return []byte{}
+    }
+    // Seek when going backwards, or when a checkpoint is closer than the current line
+    cp := sort.Search(len(s.checkpoints), func(i int) bool { return s.checkpoints[i].index > index }) - 1
+    if !s.loaded || index < s.lineStart || (cp >= 0 && s.checkpoints[cp].index > s.lineStart+s.lineCount) {
+        if err := s.seek(index); err != nil {
+            return []byte{}
+        }
+    }
+    for index >= s.lineStart+s.lineCount {
+        if !s.advance() {
+            return []byte{}
+        }
+    }
+    return s.lineValue(s.line, index-s.lineStart)
+}
+
+// Position will return the current position in the input list
+func (s *StreamWordlistInput) Position() int {
+    return s.position
+}
+
+// ResetPosition resets the position back to beginning of the wordlist. The file is not read again,
+// the reader seeks back to the beginning when the next value is read.
+func (s *StreamWordlistInput) ResetPosition() {
+    s.position = 0
+}
+
+// Keyword returns the keyword assigned to this InternalInputProvider
+func (s *StreamWordlistInput) Keyword() string {
+    return s.keyword
+}
+
+// Next will increment the cursor position, and return a boolean telling if there's words left in the list
+func (s *StreamWordlistInput) Next() bool {
+    if s.position >= s.total {
+        return false
+    }
+    return true
+}
+
+// IncrementPosition will increment the current position in the inputprovider
+func (s *StreamWordlistInput) IncrementPosition() {
+    s.position += 1
+}
+
+// Value returns the value from wordlist at current cursor position
+func (s *StreamWordlistInput) Value() []byte {
+    value := s.valueAt(s.position)
+    if s.position >= s.total-1 {
+        // The input is exhausted, the file is opened again if it is reset
+        s.Close()
+    }
+    return value
+}
+
+// Close closes the wordlist file. It is opened again if more values are read.
+func (s *StreamWordlistInput) Close() error {
+    if s.file == nil {
+        return nil
+    }
+    err := s.file.Close()
+    s.file = nil
+    s.loaded = false
+    return err
+}
+
+// Sample returns up to count values from the beginning of the wordlist
+func (s *StreamWordlistInput) Sample(count int) [][]byte {
+    if count > s.total {
+        count = s.total
+    }
+    values := make([][]byte, 0, count)
+    for i := 0; i < count; i++ {
+        values = append(values, s.valueAt(i))
+    }
+    return values
+}
+
+// Total returns the amount of values in the wordlist
+func (s *StreamWordlistInput) Total() int {
+    return s.total
+}
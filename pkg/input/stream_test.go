@@ -0,0 +1,135 @@
+package input
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// streamTestWordlist writes a wordlist spanning several checkpoints, with comments, %EXT% lines,
+// empty lines and CRLF line endings
+func streamTestWordlist(t *testing.T) string {
+    f, err := ioutil.TempFile("", "ffuf-stream")
+    if err != nil {
+        t.Fatalf("Could not create the temp file: %s", err)
+    }
+    defer f.Close()
+    var buf bytes.Buffer
+    for i := 0; i < 3*streamCheckpointInterval+100; i++ {
+        switch i % 7 {
+        case 0:
+            fmt.Fprintf(&buf, "# comment %d\n", i)
+        case 1:
+            fmt.Fprintf(&buf, "admin%d.%%EXT%%\n", i)
+        case 2:
+            fmt.Fprintf(&buf, "word%d #trailing comment\n", i)
+        case 3:
+            fmt.Fprintf(&buf, "crlf%d\r\n", i)
+        case 4:
+            buf.WriteString("\n")
+        case 5:
+            fmt.Fprintf(&buf, "  # indented comment %d\n", i)
+        default:
+            fmt.Fprintf(&buf, "word%d\n", i)
+        }
+    }
+    // No line ending on the last line
+    buf.WriteString("last")
+    f.Write(buf.Bytes())
+    return f.Name()
+}
+
+func TestStreamWordlistInput(t *testing.T) {
+    path := streamTestWordlist(t)
+    defer os.Remove(path)
+
+    for _, test := range []struct {
+        name       string
+        keyword    string
+        extensions []string
+        comments   bool
+        dirsearch  bool
+    }{
+        {name: "plain", keyword: "FUZZ"},
+        {name: "comments", keyword: "FUZZ", comments: true},
+        {name: "extensions", keyword: "FUZZ", extensions: []string{".php", ".bak"}},
+        {name: "extensions for another keyword", keyword: "W2", extensions: []string{".php", ".bak"}},
+        {name: "extensions and comments", keyword: "FUZZ", extensions: []string{".php"}, comments: true},
+        {name: "dirsearch", keyword: "FUZZ", extensions: []string{"php", "asp", "bak"}, dirsearch: true},
+        {name: "dirsearch and comments", keyword: "FUZZ", extensions: []string{"php", "asp"}, dirsearch: true, comments: true},
+    } {
+        conf := ffuf.NewConfig(context.Background())
+        conf.Extensions = test.extensions
+        conf.IgnoreWordlistComments = test.comments
+        conf.DirSearchCompat = test.dirsearch
+        want, err := NewWordlistInput(test.keyword, path, &conf)
+        if err != nil {
+            t.Fatalf("%s: could not read the wordlist: %s", test.name, err)
+        }
+        got, err := NewStreamWordlistInput(test.keyword, path, &conf)
+        if err != nil {
+            t.Fatalf("%s: could not index the wordlist: %s", test.name, err)
+        }
+        if got.Total() != want.Total() {
+            t.Errorf("%s: expected a total of %d, got %d", test.name, want.Total(), got.Total())
+            continue
+        }
+        for i, v := range got.Sample(10) {
+            if w := want.Sample(10)[i]; !bytes.Equal(v, w) {
+                t.Errorf("%s: expected the sample value %d to be %q, got %q", test.name, i, w, v)
+            }
+        }
+        // Read everything twice, the second time after seeking back to the beginning
+        for round := 0; round < 2; round++ {
+            want.ResetPosition()
+            got.ResetPosition()
+            for want.Next() {
+                if !got.Next() {
+                    t.Fatalf("%s: ran out of values at position %d", test.name, want.Position())
+                }
+                if !bytes.Equal(got.Value(), want.Value()) {
+                    t.Fatalf("%s: expected %q at position %d, got %q", test.name, want.Value(), want.Position(), got.Value())
+                }
+                want.IncrementPosition()
+                got.IncrementPosition()
+            }
+            if got.Next() {
+                t.Errorf("%s: expected no values after position %d", test.name, got.Position())
+            }
+            if got.file != nil {
+                t.Errorf("%s: expected the file to be closed once the last value was read", test.name)
+            }
+        }
+    }
+}
+
+func TestStreamWordlistInputClose(t *testing.T) {
+    path := streamTestWordlist(t)
+    defer os.Remove(path)
+    conf := ffuf.NewConfig(context.Background())
+    conf.StreamWordlists = true
+    ip, _ := NewInputProvider(&conf)
+    if err := ip.AddProvider(ffuf.InputProviderConfig{Name: "wordlist", Value: path, Keyword: "FUZZ"}); err != nil {
+        t.Fatalf("Could not add the wordlist: %s", err)
+    }
+    stream := ip.(*MainInputProvider).Providers[0].(*StreamWordlistInput)
+    // The job ends before the input does
+    ip.Next()
+    first := string(ip.Value()["FUZZ"])
+    ip.Close()
+    if stream.file != nil {
+        t.Fatalf("Expected the file to be closed with the input")
+    }
+    // The next job reads the input again
+    ip.Reset()
+    ip.Next()
+    if value := string(ip.Value()["FUZZ"]); value != first {
+        t.Errorf("Expected %q after opening the file again, got %q", first, value)
+    }
+    ip.Close()
+}
@@ -0,0 +1,294 @@
+package input
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// TableInput reads a CSV, TSV or JSONL file and binds its columns to several keywords, so every
+// row is one input with the values of all of the keywords, eg. "-w creds.csv:USER,PASS".
+type TableInput struct {
+    config   *ffuf.Config
+    keywords []string
+    rows     [][][]byte
+    position int
+}
+
+// tableColumn is the column of a keyword: a name in the header row or the JSON object, or an index
+type tableColumn struct {
+    name  string
+    index int
+}
+
+// NewTableInput reads the table. The columns are given as a comma separated list of keywords,
+// each optionally followed by the column it is bound to: a header name or a 1-based number, eg.
+// "USER=username,PASS=3". The keywords are bound to the columns in order otherwise, unless the
+// first row of the file names all of them, in which case it is used as the header row.
+func NewTableInput(value string, columns string, conf *ffuf.Config) (*TableInput, error) {
+    t := &TableInput{config: conf}
+    bindings := make([]tableColumn, 0)
+    for i, c := range strings.Split(columns, ",") {
+        kw := strings.SplitN(c, "=", 2)
+        if kw[0] == "" {
+            return t, fmt.Errorf("Empty keyword in the columns %s of %s", columns, value)
+        }
+        col := tableColumn{index: i}
+        if len(kw) == 2 {
+            if n, err := strconv.Atoi(kw[1]); err == nil && n > 0 {
+                col.index = n - 1
+            } else {
+                col = tableColumn{name: kw[1], index: -1}
+            }
+        }
+        t.keywords = append(t.keywords, kw[0])
+        bindings = append(bindings, col)
+    }
+
+    var file *os.File
+    var err error
+    if value == "-" {
+        // Stdin is left open, it is not ours to close
+        file = os.Stdin
+    } else {
+        file, err = os.Open(value)
+        if err != nil {
+            return t, err
+        }
+        defer file.Close()
+    }
+
+    switch strings.ToLower(filepath.Ext(value)) {
+    case ".jsonl", ".ndjson":
+        err = t.readJSONL(file, bindings)
+    case ".tsv":
+        err = t.readCSV(file, '\t', bindings)
+    default:
+        err = t.readCSV(file, ',', bindings)
+    }
+    if err != nil {
+        return t, fmt.Errorf("%s: %s", value, err)
+    }
+    return t, nil
+}
+
+// readCSV reads the rows of a CSV or TSV file, using the first row as the header if the columns
+// are bound by name
+func (t *TableInput) readCSV(file io.Reader, comma rune, bindings []tableColumn) error {
+    reader := csv.NewReader(file)
+    reader.Comma = comma
+    reader.FieldsPerRecord = -1
+    if comma == '\t' {
+        // Quotes have no special meaning in most of the TSV files
+        reader.LazyQuotes = true
+    }
+    if t.config.IgnoreWordlistComments {
+        reader.Comment = '#'
+    }
+    records, err := reader.ReadAll()
+    if err != nil {
+        return err
+    }
+    if len(records) > 0 && t.headerRow(records[0], bindings) {
+        for i, b := range bindings {
+            if b.name == "" {
+                continue
+            }
+            bindings[i].index = -1
+            for j, h := range records[0] {
+                if strings.EqualFold(strings.TrimSpace(h), b.name) {
+                    bindings[i].index = j
+                    break
+                }
+            }
+            if bindings[i].index == -1 {
+                return fmt.Errorf("column %s not found in the header row", b.name)
+            }
+        }
+        records = records[1:]
+    }
+    for n, record := range records {
+        row := make([][]byte, 0, len(bindings))
+        for i, b := range bindings {
+            if b.index >= len(record) {
+                return fmt.Errorf("row %d has no column %d for %s", n+1, b.index+1, t.keywords[i])
+            }
+            row = append(row, []byte(record[b.index]))
+        }
+        t.rows = append(t.rows, row)
+    }
+    return nil
+}
+
+// headerRow tells if the first row is the header: the columns are bound by name, or the row
+// has all of the keywords in it. The keywords without a name are then bound to their header.
+func (t *TableInput) headerRow(first []string, bindings []tableColumn) bool {
+    named := false
+    for _, b := range bindings {
+        if b.name != "" {
+            named = true
+        }
+    }
+    if named {
+        return true
+    }
+    for _, kw := range t.keywords {
+        found := false
+        for _, h := range first {
+            if strings.EqualFold(strings.TrimSpace(h), kw) {
+                found = true
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    for i, kw := range t.keywords {
+        bindings[i].name = kw
+    }
+    return true
+}
+
+// readJSONL reads a JSON object or array per line. The keywords are bound to the fields of their
+// own name, case-insensitively, unless another field is given.
+func (t *TableInput) readJSONL(file io.Reader, bindings []tableColumn) error {
+    reader := bufio.NewScanner(file)
+    reader.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+    line := 0
+    for reader.Scan() {
+        line++
+        text := bytes.TrimSpace(reader.Bytes())
+        if len(text) == 0 {
+            continue
+        }
+        var doc interface{}
+        if err := jsoniter.Unmarshal(text, &doc); err != nil {
+            return fmt.Errorf("line %d: %s", line, err)
+        }
+        row := make([][]byte, 0, len(bindings))
+        for i, b := range bindings {
+            v, ok := jsonlField(doc, b, t.keywords[i])
+            if !ok {
+                return fmt.Errorf("line %d has no field for %s", line, t.keywords[i])
+            }
+            row = append(row, v)
+        }
+        t.rows = append(t.rows, row)
+    }
+    return reader.Err()
+}
+
+// jsonlField returns the value of a field of an object, or of an element of an array, as text
+func jsonlField(doc interface{}, b tableColumn, keyword string) ([]byte, bool) {
+    var v interface{}
+    switch d := doc.(type) {
+    case map[string]interface{}:
+        name := b.name
+        if name == "" {
+            name = keyword
+        }
+        var ok bool
+        if v, ok = d[name]; !ok {
+            for k, kv := range d {
+                if strings.EqualFold(k, name) {
+                    v, ok = kv, true
+                    break
+                }
+            }
+        }
+        if !ok {
+            return nil, false
+        }
+    case []interface{}:
+        if b.index < 0 || b.index >= len(d) {
+            return nil, false
+        }
+        v = d[b.index]
+    default:
+        return nil, false
+    }
+    switch val := v.(type) {
+    case string:
+        return []byte(val), true
+    case nil:
+        return []byte{}, true
+    }
+    encoded, err := jsoniter.Marshal(v)
+    return encoded, err == nil
+}
+
+// Keywords returns all of the keywords bound to the columns
+func (t *TableInput) Keywords() []string {
+    return t.keywords
+}
+
+// Values returns the values of all of the keywords on the current row
+func (t *TableInput) Values() map[string][]byte {
+    values := make(map[string][]byte)
+    for i, kw := range t.keywords {
+        values[kw] = t.rows[t.position][i]
+    }
+    return values
+}
+
+// Keyword returns the first keyword assigned to this InternalInputProvider
+func (t *TableInput) Keyword() string {
+    return t.keywords[0]
+}
+
+// Position will return the current row
+func (t *TableInput) Position() int {
+    return t.position
+}
+
+// ResetPosition resets the position back to the first row
+func (t *TableInput) ResetPosition() {
+    t.position = 0
+}
+
+// Next will increment the cursor position, and return a boolean telling if there's rows left
+func (t *TableInput) Next() bool {
+    if t.position >= len(t.rows) {
+        return false
+    }
+    return true
+}
+
+// IncrementPosition will increment the current row
+func (t *TableInput) IncrementPosition() {
+    t.position += 1
+}
+
+// Value returns the value of the first keyword on the current row
+func (t *TableInput) Value() []byte {
+    return t.rows[t.position][0]
+}
+
+// SampleKeyword returns up to count values of a keyword from the first rows
+func (t *TableInput) SampleKeyword(keyword string, count int) [][]byte {
+    column := -1
+    for i, kw := range t.keywords {
+        if kw == keyword {
+            column = i
+        }
+    }
+    values := make([][]byte, 0)
+    for i := 0; column != -1 && i < count && i < len(t.rows); i++ {
+        values = append(values, t.rows[i][column])
+    }
+    return values
+}
+
+// Total returns the amount of rows
+func (t *TableInput) Total() int {
+    return len(t.rows)
+}
@@ -0,0 +1,227 @@
+package input
+
+import (
+    "context"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// tableRows returns the values of all of the rows of the table
+func tableRows(t *TableInput) []map[string]string {
+    rows := make([]map[string]string, 0)
+    for t.Next() {
+        row := make(map[string]string)
+        for k, v := range t.Values() {
+            row[k] = string(v)
+        }
+        rows = append(rows, row)
+        t.IncrementPosition()
+    }
+    return rows
+}
+
+func TestTableInput(t *testing.T) {
+    dir, err := ioutil.TempDir("", "ffuf-table")
+    if err != nil {
+        t.Fatalf("Could not create the temp dir: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    for _, test := range []struct {
+        name     string
+        file     string
+        content  string
+        columns  string
+        comments bool
+        want     []map[string]string
+        wantErr  bool
+    }{
+        {
+            name:    "csv by position",
+            file:    "creds.csv",
+            content: "admin,secret\nroot,toor\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "admin", "PASS": "secret"}, {"USER": "root", "PASS": "toor"}},
+        },
+        {
+            name:    "quoted fields",
+            file:    "creds.csv",
+            content: "\"a,b\",\"say \"\"hi\"\"\"\n\"multi\nline\",x\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "a,b", "PASS": "say \"hi\""}, {"USER": "multi\nline", "PASS": "x"}},
+        },
+        {
+            name:    "header naming the keywords",
+            file:    "creds.csv",
+            content: "Pass,id,user\nsecret,1,admin\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "admin", "PASS": "secret"}},
+        },
+        {
+            name:    "header names",
+            file:    "creds.csv",
+            content: "id,username,password\n1,admin,secret\n2,root,toor\n",
+            columns: "USER=username,PASS=password",
+            want:    []map[string]string{{"USER": "admin", "PASS": "secret"}, {"USER": "root", "PASS": "toor"}},
+        },
+        {
+            name:    "header name not found",
+            file:    "creds.csv",
+            content: "id,username\n1,admin\n",
+            columns: "USER=username,PASS=password",
+            wantErr: true,
+        },
+        {
+            name:    "column numbers",
+            file:    "creds.csv",
+            content: "1,admin,secret\n",
+            columns: "PASS=3,USER=2",
+            want:    []map[string]string{{"USER": "admin", "PASS": "secret"}},
+        },
+        {
+            name:    "row with fewer columns",
+            file:    "creds.csv",
+            content: "admin,secret\nroot\n",
+            columns: "USER,PASS",
+            wantErr: true,
+        },
+        {
+            name:    "row with more columns",
+            file:    "creds.csv",
+            content: "admin,secret,extra\nroot,toor\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "admin", "PASS": "secret"}, {"USER": "root", "PASS": "toor"}},
+        },
+        {
+            name:     "comments",
+            file:     "creds.csv",
+            content:  "# users\nadmin,secret\n",
+            columns:  "USER,PASS",
+            comments: true,
+            want:     []map[string]string{{"USER": "admin", "PASS": "secret"}},
+        },
+        {
+            name:    "tsv",
+            file:    "creds.tsv",
+            content: "ad\"min\tse,cret\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "ad\"min", "PASS": "se,cret"}},
+        },
+        {
+            name:    "empty keyword",
+            file:    "creds.csv",
+            content: "admin,secret\n",
+            columns: "USER,",
+            wantErr: true,
+        },
+        {
+            name:    "jsonl fields",
+            file:    "creds.jsonl",
+            content: "{\"user\": \"admin\", \"Pass\": 1234}\n\n{\"user\": \"root\", \"pass\": null, \"extra\": [1]}\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "admin", "PASS": "1234"}, {"USER": "root", "PASS": ""}},
+        },
+        {
+            name:    "jsonl field names",
+            file:    "creds.ndjson",
+            content: "{\"login\": {\"name\": \"admin\"}, \"password\": \"secret\"}\n",
+            columns: "USER=login,PASS=password",
+            want:    []map[string]string{{"USER": "{\"name\":\"admin\"}", "PASS": "secret"}},
+        },
+        {
+            name:    "jsonl arrays",
+            file:    "creds.jsonl",
+            content: "[\"admin\", \"secret\"]\n",
+            columns: "USER,PASS",
+            want:    []map[string]string{{"USER": "admin", "PASS": "secret"}},
+        },
+        {
+            name:    "jsonl missing field",
+            file:    "creds.jsonl",
+            content: "{\"user\": \"admin\", \"pass\": \"secret\"}\n{\"user\": \"root\"}\n",
+            columns: "USER,PASS",
+            wantErr: true,
+        },
+        {
+            name:    "jsonl invalid line",
+            file:    "creds.jsonl",
+            content: "{\"user\": \"admin\"\n",
+            columns: "USER",
+            wantErr: true,
+        },
+    } {
+        path := filepath.Join(dir, test.file)
+        if err := ioutil.WriteFile(path, []byte(test.content), 0644); err != nil {
+            t.Fatalf("Could not write the table: %s", err)
+        }
+        conf := ffuf.NewConfig(context.Background())
+        conf.IgnoreWordlistComments = test.comments
+        table, err := NewTableInput(path, test.columns, &conf)
+        if test.wantErr {
+            if err == nil {
+                t.Errorf("%s: expected an error", test.name)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.name, err)
+            continue
+        }
+        if table.Total() != len(test.want) {
+            t.Errorf("%s: expected a total of %d, got %d", test.name, len(test.want), table.Total())
+        }
+        if got := tableRows(table); !reflect.DeepEqual(got, test.want) {
+            t.Errorf("%s: expected the rows %q, got %q", test.name, test.want, got)
+        }
+    }
+}
+
+func TestTableInputProviders(t *testing.T) {
+    f, err := ioutil.TempFile("", "ffuf-table*.csv")
+    if err != nil {
+        t.Fatalf("Could not create the temp file: %s", err)
+    }
+    defer os.Remove(f.Name())
+    f.WriteString("admin,secret\nroot,toor\n")
+    f.Close()
+
+    conf := ffuf.NewConfig(context.Background())
+    conf.InputMode = "clusterbomb"
+    ip, _ := NewInputProvider(&conf)
+    // The table is given once for each of its keywords, eg. "-w creds.csv:USER,PASS"
+    for _, kw := range []string{"USER", "PASS"} {
+        if err := ip.AddProvider(ffuf.InputProviderConfig{Name: "table", Value: f.Name(), Keyword: kw, Columns: "USER,PASS"}); err != nil {
+            t.Fatalf("Could not add the table for %s: %s", kw, err)
+        }
+    }
+    if n := len(ip.(*MainInputProvider).Providers); n != 1 {
+        t.Errorf("Expected the table to be read once, got %d providers", n)
+    }
+    if ip.Total() != 2 {
+        t.Errorf("Expected a total of 2 rows, got %d", ip.Total())
+    }
+    values, _ := inputValues(ip)
+    want := []map[string]string{{"USER": "admin", "PASS": "secret"}, {"USER": "root", "PASS": "toor"}}
+    if !reflect.DeepEqual(values, want) {
+        t.Errorf("Expected the rows %q, got %q", want, values)
+    }
+    if sample := ip.Sample("PASS", 5); len(sample) != 2 || string(sample[1]) != "toor" {
+        t.Errorf("Expected the PASS column as the sample, got %q", sample)
+    }
+
+    // A table failing to load is reported once
+    ip, _ = NewInputProvider(&conf)
+    missing := ffuf.InputProviderConfig{Name: "table", Value: f.Name() + ".missing", Keyword: "USER", Columns: "USER,PASS"}
+    if err := ip.AddProvider(missing); err == nil {
+        t.Errorf("Expected an error for the missing table")
+    }
+    missing.Keyword = "PASS"
+    if err := ip.AddProvider(missing); err != nil {
+        t.Errorf("Expected the error of the missing table only once, got %s", err)
+    }
+}
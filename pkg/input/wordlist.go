@@ -73,6 +73,14 @@ func (w *WordlistInput) Value() []byte {
     return w.data[w.position]
 }
 
+// Sample returns up to count values from the beginning of the wordlist
+func (w *WordlistInput) Sample(count int) [][]byte {
+    if count > len(w.data) {
+        count = len(w.data)
+    }
+    return w.data[:count]
+}
+
 // Total returns the size of wordlist
 func (w *WordlistInput) Total() int {
     return len(w.data)
@@ -5,59 +5,101 @@ import (
     "encoding/csv"
     "os"
     "strconv"
+    "strings"
 
     "github.com/theblackturtle/ffuf/pkg/ffuf"
 )
 
-var staticheaders = []string{"url", "redirectlocation", "position", "status_code", "content_length", "content_words", "content_lines", "resultfile"}
+var staticheaders = []string{"url", "redirectlocation", "position", "status_code", "content_length", "content_words", "content_lines", "resultfile", "duration", "ttfb", "reflected", "reflections"}
 
-func writeCSV(config *ffuf.Config, res []Result, encode bool) error {
+// csvWriter writes the results to a CSV file, flushing every row as soon as it is produced
+type csvWriter struct {
+    f        *os.File
+    w        *csv.Writer
+    keywords []string
+    encode   bool
+    active   bool
+    errors   bool
+}
+
+func newCSVWriter(config *ffuf.Config, filename string, encode bool) (*csvWriter, error) {
     header := make([]string, 0)
-    f, err := os.Create(config.OutputFile)
+    f, err := os.Create(filename)
     if err != nil {
-        return err
+        return nil, err
+    }
+    c := &csvWriter{
+        f:        f,
+        w:        csv.NewWriter(f),
+        keywords: make([]string, 0),
+        encode:   encode,
+        active:   config.InputMode == "sniper",
+        errors:   config.OutputErrors,
     }
-    defer f.Close()
-
-    w := csv.NewWriter(f)
-    defer w.Flush()
 
     for _, inputprovider := range config.InputProviders {
+        c.keywords = append(c.keywords, inputprovider.Keyword)
         header = append(header, inputprovider.Keyword)
     }
-
     for _, item := range staticheaders {
         header = append(header, item)
     }
+    if c.active {
+        header = append(header, "active_keyword")
+    }
+    if c.errors {
+        header = append(header, "error_class", "error")
+    }
 
-    if err := w.Write(header); err != nil {
-        return err
+    if err := c.w.Write(header); err != nil {
+        f.Close()
+        return nil, err
     }
-    for _, r := range res {
-        if encode {
-            inputs := make(map[string][]byte, 0)
-            for k, v := range r.Input {
-                inputs[k] = []byte(base64encode(v))
-            }
-            r.Input = inputs
-        }
+    c.w.Flush()
+    return c, c.w.Error()
+}
 
-        err := w.Write(toCSV(r))
-        if err != nil {
-            return err
+func (c *csvWriter) Write(r Result) error {
+    if c.encode {
+        inputs := make(map[string][]byte, 0)
+        for k, v := range r.Input {
+            inputs[k] = []byte(base64encode(v))
         }
+        r.Input = inputs
     }
-    return nil
+
+    record := toCSV(r, c.keywords)
+    if c.active {
+        record = append(record, r.Active)
+    }
+    if c.errors {
+        record = append(record, r.ErrorClass, r.Error)
+    }
+    if err := c.w.Write(record); err != nil {
+        return err
+    }
+    c.w.Flush()
+    return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+    c.w.Flush()
+    if err := c.w.Error(); err != nil {
+        c.f.Close()
+        return err
+    }
+    return c.f.Close()
 }
 
 func base64encode(in []byte) string {
     return base64.StdEncoding.EncodeToString(in)
 }
 
-func toCSV(r Result) []string {
+func toCSV(r Result, keywords []string) []string {
     res := make([]string, 0)
-    for _, v := range r.Input {
-        res = append(res, string(v))
+    // Keep the input columns in the same order as the header
+    for _, k := range keywords {
+        res = append(res, string(r.Input[k]))
     }
     res = append(res, r.Url)
     res = append(res, r.RedirectLocation)
@@ -67,5 +109,13 @@ func toCSV(r Result) []string {
     res = append(res, strconv.FormatInt(r.ContentWords, 10))
     res = append(res, strconv.FormatInt(r.ContentLines, 10))
     res = append(res, r.ResultFile)
+    res = append(res, strconv.FormatInt(r.Duration, 10))
+    res = append(res, strconv.FormatInt(r.TimeToFirstByte, 10))
+    res = append(res, strconv.FormatBool(r.Reflected))
+    reflections := make([]string, 0)
+    for _, refl := range r.Reflections {
+        reflections = append(reflections, refl.String())
+    }
+    res = append(res, strings.Join(reflections, ";"))
     return res
 }
@@ -13,6 +13,8 @@ type htmlFileOutput struct {
     Time        string
     Keys        []string
     Results     []Result
+    Errors      bool
+    Dedup       bool
 }
 
 const (
@@ -77,8 +79,11 @@ const (
               <th>Length</th>
               <th>Words</th>
               <th>Lines</th>
+              <th>Duration (ms)</th>
 			  <th>Resultfile</th>
-          </tr>
+{{ if .Dedup }}			  <th>Duplicates</th>
+{{ end }}{{ if .Errors }}			  <th>Error</th>
+{{ end }}          </tr>
         </thead>
 
         <tbody>
@@ -86,7 +91,7 @@ const (
                 <div style="display:none">
 |result_raw|{{ $result.StatusCode }}{{ range $keyword, $value := $result.Input }}|{{ $value | printf "%s" }}{{ end }}|{{ $result.Url }}|{{ $result.RedirectLocation }}|{{ $result.Position }}|{{ $result.ContentLength }}|{{ $result.ContentWords }}|{{ $result.ContentLines }}|
                 </div>
-				<tr class="result-{{ $result.StatusCode }}" style="background-color: {{$result.HTMLColor}};"><td><font color="black" class="status-code">{{ $result.StatusCode }}</font></td>{{ range $keyword, $value := $result.Input }}<td>{{ $value | printf "%s" }}</td>{{ end }}</td><td>{{ $result.Url }}</td><td>{{ $result.RedirectLocation }}</td><td>{{ $result.Position }}</td><td>{{ $result.ContentLength }}</td><td>{{ $result.ContentWords }}</td><td>{{ $result.ContentLines }}</td><td>{{ $result.ResultFile }}</td></tr>
+				<tr class="result-{{ $result.StatusCode }}" style="background-color: {{$result.HTMLColor}};"><td><font color="black" class="status-code">{{ $result.StatusCode }}</font></td>{{ range $keyword, $value := $result.Input }}<td>{{ $value | printf "%s" }}</td>{{ end }}</td><td>{{ $result.Url }}</td><td>{{ $result.RedirectLocation }}</td><td>{{ $result.Position }}</td><td>{{ $result.ContentLength }}</td><td>{{ $result.ContentWords }}</td><td>{{ $result.ContentLines }}</td><td>{{ $result.Duration }}</td><td>{{ $result.ResultFile }}</td>{{ if $.Dedup }}<td>{{ $result.Duplicates }}{{ range $input := $result.DuplicateInputs }}<br />{{ range $keyword, $value := $input }}{{ $keyword }}: {{ $value | printf "%s" }} {{ end }}{{ end }}</td>{{ end }}{{ if $.Errors }}<td>{{ $result.ErrorClass }}</td>{{ end }}</tr>
             {{end}}
         </tbody>
       </table>
@@ -148,13 +153,17 @@ func colorizeResults(results []Result) []Result {
             result.HTMLColor = "#de8dc1"
         }
 
+        if result.ErrorClass != "" {
+            result.HTMLColor = "#f5a3a3"
+        }
+
         newResults = append(newResults, result)
     }
 
     return newResults
 }
 
-func writeHTML(config *ffuf.Config, results []Result) error {
+func writeHTML(config *ffuf.Config, filename string, results []Result) error {
 
     results = colorizeResults(results)
 
@@ -170,9 +179,11 @@ func writeHTML(config *ffuf.Config, results []Result) error {
         Time:        ti.Format(time.RFC3339),
         Results:     results,
         Keys:        keywords,
+        Errors:      config.OutputErrors,
+        Dedup:       config.Dedup,
     }
 
-    f, err := os.Create(config.OutputFile)
+    f, err := os.Create(filename)
     if err != nil {
         return err
     }
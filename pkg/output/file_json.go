@@ -12,33 +12,45 @@ import (
 type ejsonFileOutput struct {
     CommandLine string   `json:"commandline"`
     Time        string   `json:"time"`
+    Format      string   `json:"format"`
     Results     []Result `json:"results"`
 }
 
 type JsonResult struct {
-    Input            map[string]string `json:"input"`
-    Position         int               `json:"position"`
-    StatusCode       int64             `json:"status"`
-    ContentLength    int64             `json:"length"`
-    ContentWords     int64             `json:"words"`
-    ContentLines     int64             `json:"lines"`
-    RedirectLocation string            `json:"redirectlocation"`
-    ResultFile       string            `json:"resultfile"`
-    Url              string            `json:"url"`
+    Input            map[string]string   `json:"input"`
+    Position         int                 `json:"position"`
+    Active           string              `json:"active_keyword,omitempty"`
+    StatusCode       int64               `json:"status"`
+    ContentLength    int64               `json:"length"`
+    ContentWords     int64               `json:"words"`
+    ContentLines     int64               `json:"lines"`
+    RedirectLocation string              `json:"redirectlocation"`
+    ResultFile       string              `json:"resultfile"`
+    Url              string              `json:"url"`
+    Duration         int64               `json:"duration"`
+    TimeToFirstByte  int64               `json:"ttfb"`
+    Reflected        bool                `json:"reflected"`
+    Reflections      []ffuf.Reflection   `json:"reflections,omitempty"`
+    Duplicates       int                 `json:"duplicates,omitempty"`
+    DuplicateInputs  []map[string]string `json:"duplicate_inputs,omitempty"`
+    ErrorClass       string              `json:"error_class,omitempty"`
+    Error            string              `json:"error,omitempty"`
 }
 
 type jsonFileOutput struct {
     CommandLine string       `json:"commandline"`
     Time        string       `json:"time"`
+    Format      string       `json:"format"`
     Results     []JsonResult `json:"results"`
     Config      *ffuf.Config `json:"config"`
 }
 
-func writeEJSON(config *ffuf.Config, res []Result) error {
+func writeEJSON(config *ffuf.Config, filename string, res []Result) error {
     t := time.Now()
     outJSON := ejsonFileOutput{
         CommandLine: config.CommandLine,
         Time:        t.Format(time.RFC3339),
+        Format:      "ejson",
         Results:     res,
     }
 
@@ -46,36 +58,23 @@ func writeEJSON(config *ffuf.Config, res []Result) error {
     if err != nil {
         return err
     }
-    err = ioutil.WriteFile(config.OutputFile, outBytes, 0644)
+    err = ioutil.WriteFile(filename, outBytes, 0644)
     if err != nil {
         return err
     }
     return nil
 }
 
-func writeJSON(config *ffuf.Config, res []Result) error {
+func writeJSON(config *ffuf.Config, filename string, res []Result) error {
     t := time.Now()
     jsonRes := make([]JsonResult, 0)
     for _, r := range res {
-        strinput := make(map[string]string)
-        for k, v := range r.Input {
-            strinput[k] = string(v)
-        }
-        jsonRes = append(jsonRes, JsonResult{
-            Input:            strinput,
-            Position:         r.Position,
-            StatusCode:       r.StatusCode,
-            ContentLength:    r.ContentLength,
-            ContentWords:     r.ContentWords,
-            ContentLines:     r.ContentLines,
-            RedirectLocation: r.RedirectLocation,
-            ResultFile:       r.ResultFile,
-            Url:              r.Url,
-        })
+        jsonRes = append(jsonRes, toJsonResult(r))
     }
     outJSON := jsonFileOutput{
         CommandLine: config.CommandLine,
         Time:        t.Format(time.RFC3339),
+        Format:      "json",
         Results:     jsonRes,
         Config:      config,
     }
@@ -83,9 +82,45 @@ func writeJSON(config *ffuf.Config, res []Result) error {
     if err != nil {
         return err
     }
-    err = ioutil.WriteFile(config.OutputFile, outBytes, 0644)
+    err = ioutil.WriteFile(filename, outBytes, 0644)
     if err != nil {
         return err
     }
     return nil
 }
+
+// toJsonResult converts the result to its json representation, with the inputs as strings
+func toJsonResult(r Result) JsonResult {
+    strinput := make(map[string]string)
+    for k, v := range r.Input {
+        strinput[k] = string(v)
+    }
+    duplicates := make([]map[string]string, 0)
+    for _, input := range r.DuplicateInputs {
+        strdup := make(map[string]string)
+        for k, v := range input {
+            strdup[k] = string(v)
+        }
+        duplicates = append(duplicates, strdup)
+    }
+    return JsonResult{
+        Input:            strinput,
+        Position:         r.Position,
+        Active:           r.Active,
+        StatusCode:       r.StatusCode,
+        ContentLength:    r.ContentLength,
+        ContentWords:     r.ContentWords,
+        ContentLines:     r.ContentLines,
+        RedirectLocation: r.RedirectLocation,
+        ResultFile:       r.ResultFile,
+        Url:              r.Url,
+        Duration:         r.Duration,
+        TimeToFirstByte:  r.TimeToFirstByte,
+        Reflected:        r.Reflected,
+        Reflections:      r.Reflections,
+        Duplicates:       r.Duplicates,
+        DuplicateInputs:  duplicates,
+        ErrorClass:       r.ErrorClass,
+        Error:            r.Error,
+    }
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+    "os"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// jsonlWriter writes every result as a JSON object on a line of its own, as soon as it is produced
+type jsonlWriter struct {
+    f *os.File
+}
+
+func newJSONLWriter(config *ffuf.Config, filename string) (*jsonlWriter, error) {
+    f, err := os.Create(filename)
+    if err != nil {
+        return nil, err
+    }
+    return &jsonlWriter{f: f}, nil
+}
+
+func (j *jsonlWriter) Write(r Result) error {
+    outBytes, err := jsoniter.Marshal(toJsonResult(r))
+    if err != nil {
+        return err
+    }
+    _, err = j.f.Write(append(outBytes, '\n'))
+    return err
+}
+
+func (j *jsonlWriter) Close() error {
+    return j.f.Close()
+}
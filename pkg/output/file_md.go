@@ -14,13 +14,13 @@ const (
   Command line : ` + "`{{.CommandLine}}`" + `
   Time: ` + "{{ .Time }}" + `
 
-  {{ range .Keys }}| {{ . }} {{ end }}| URL | Redirectlocation | Position | Status Code | Content Length | Content Words | Content Lines | ResultFile |
-  {{ range .Keys }}| :- {{ end }}| :-- | :--------------- | :---- | :------- | :---------- | :------------- | :------------ | :--------- |
-  {{range .Results}}{{ range $keyword, $value := .Input }}| {{ $value | printf "%s" }} {{ end }}| {{ .Url }} | {{ .RedirectLocation }} | {{ .Position }} | {{ .StatusCode }} | {{ .ContentLength }} | {{ .ContentWords }} | {{ .ContentLines }} | {{ .ResultFile }} |
+  {{ range .Keys }}| {{ . }} {{ end }}| URL | Redirectlocation | Position | Status Code | Content Length | Content Words | Content Lines | Duration (ms) | ResultFile |{{ if .Errors }} Error |{{ end }}
+  {{ range .Keys }}| :- {{ end }}| :-- | :--------------- | :---- | :------- | :---------- | :------------- | :------------ | :------------ | :--------- |{{ if .Errors }} :---- |{{ end }}
+  {{range .Results}}{{ range $keyword, $value := .Input }}| {{ $value | printf "%s" }} {{ end }}| {{ .Url }} | {{ .RedirectLocation }} | {{ .Position }} | {{ .StatusCode }} | {{ .ContentLength }} | {{ .ContentWords }} | {{ .ContentLines }} | {{ .Duration }} | {{ .ResultFile }} |{{ if $.Errors }} {{ .ErrorClass }} |{{ end }}
   {{end}}` // The template format is not pretty but follows the markdown guide
 )
 
-func writeMarkdown(config *ffuf.Config, res []Result) error {
+func writeMarkdown(config *ffuf.Config, filename string, res []Result) error {
 
     ti := time.Now()
 
@@ -34,9 +34,10 @@ func writeMarkdown(config *ffuf.Config, res []Result) error {
         Time:        ti.Format(time.RFC3339),
         Results:     res,
         Keys:        keywords,
+        Errors:      config.OutputErrors,
     }
 
-    f, err := os.Create(config.OutputFile)
+    f, err := os.Create(filename)
     if err != nil {
         return err
     }
@@ -1,10 +1,36 @@
 package output
 
 import (
+    "path/filepath"
+    "strings"
+
     "github.com/theblackturtle/ffuf/pkg/ffuf"
 )
 
+// Formats lists the available output file formats
+var Formats = []string{"json", "ejson", "jsonl", "html", "md", "csv", "ecsv"}
+
 func NewOutputProviderByName(name string, conf *ffuf.Config) ffuf.OutputProvider {
     // We have only one outputprovider at the moment
     return NewStdoutput(conf)
 }
+
+// streamWriter writes the results to the output file as they come in, instead of at Finalize
+type streamWriter interface {
+    Write(r Result) error
+    Close() error
+}
+
+// outputFilename returns the file a format is written to. A single format goes to the output file
+// (-o) as is, with multiple formats every format gets a file of its own named after the output file.
+func outputFilename(conf *ffuf.Config, format string) string {
+    if len(conf.OutputFormats) < 2 {
+        return conf.OutputFile
+    }
+    base := conf.OutputFile
+    ext := filepath.Ext(base)
+    if inSlice(strings.TrimPrefix(ext, "."), Formats) {
+        base = strings.TrimSuffix(base, ext)
+    }
+    return base + "." + format
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+    "context"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestOutputFilename(t *testing.T) {
+    for _, test := range []struct {
+        file    string
+        formats []string
+        want    []string
+    }{
+        {file: "out.json", formats: []string{"json"}, want: []string{"out.json"}},
+        {file: "out.txt", formats: []string{"html"}, want: []string{"out.txt"}},
+        {file: "out.json", formats: []string{"json", "html", "csv"}, want: []string{"out.json", "out.html", "out.csv"}},
+        {file: "results/out.ecsv", formats: []string{"jsonl", "ecsv"}, want: []string{"results/out.jsonl", "results/out.ecsv"}},
+        {file: "out", formats: []string{"json", "md"}, want: []string{"out.json", "out.md"}},
+        {file: "out.txt", formats: []string{"json", "md"}, want: []string{"out.txt.json", "out.txt.md"}},
+        {file: "scan.v2.json", formats: []string{"json", "jsonl"}, want: []string{"scan.v2.json", "scan.v2.jsonl"}},
+    } {
+        conf := ffuf.NewConfig(context.Background())
+        conf.OutputFile = test.file
+        conf.OutputFormats = test.formats
+        for i, format := range test.formats {
+            if got := outputFilename(&conf, format); got != test.want[i] {
+                t.Errorf("-o %s -of %s: expected %s for %s, got %s", test.file, strings.Join(test.formats, ","), test.want[i], format, got)
+            }
+        }
+    }
+}
+
+func TestStreamWriters(t *testing.T) {
+    dir, err := ioutil.TempDir("", "ffuf-output")
+    if err != nil {
+        t.Fatalf("Could not create the temp dir: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    conf := ffuf.NewConfig(context.Background())
+    conf.OutputFile = filepath.Join(dir, "out.json")
+    conf.OutputFormats = []string{"jsonl", "csv"}
+    conf.InputProviders = []ffuf.InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}}
+    results := []Result{
+        {Input: map[string][]byte{"FUZZ": []byte("admin")}, Position: 1, StatusCode: 200, Url: "http://localhost/admin"},
+        {Input: map[string][]byte{"FUZZ": []byte("a,\"b\"")}, Position: 2, StatusCode: 403, Url: "http://localhost/a"},
+    }
+
+    jsonl, err := newJSONLWriter(&conf, outputFilename(&conf, "jsonl"))
+    if err != nil {
+        t.Fatalf("Could not create the jsonl writer: %s", err)
+    }
+    defer jsonl.Close()
+    csv, err := newCSVWriter(&conf, outputFilename(&conf, "csv"), false)
+    if err != nil {
+        t.Fatalf("Could not create the csv writer: %s", err)
+    }
+    defer csv.Close()
+
+    for i, r := range results {
+        if err := jsonl.Write(r); err != nil {
+            t.Fatalf("Could not write the jsonl result: %s", err)
+        }
+        if err := csv.Write(r); err != nil {
+            t.Fatalf("Could not write the csv result: %s", err)
+        }
+        // Every result is on the disk before the writers are closed
+        content, _ := ioutil.ReadFile(filepath.Join(dir, "out.jsonl"))
+        lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+        if len(lines) != i+1 {
+            t.Fatalf("Expected %d jsonl lines, got %d", i+1, len(lines))
+        }
+        var got JsonResult
+        if err := jsoniter.Unmarshal([]byte(lines[i]), &got); err != nil {
+            t.Fatalf("Could not parse the jsonl line %q: %s", lines[i], err)
+        }
+        if got.Input["FUZZ"] != string(r.Input["FUZZ"]) || got.StatusCode != r.StatusCode || got.Position != r.Position {
+            t.Errorf("Expected the jsonl line to hold %v, got %s", r, lines[i])
+        }
+        content, _ = ioutil.ReadFile(filepath.Join(dir, "out.csv"))
+        if rows := strings.Count(string(content), "\n"); rows != i+2 {
+            t.Errorf("Expected the csv header and %d rows, got %q", i+1, content)
+        }
+    }
+    content, _ := ioutil.ReadFile(filepath.Join(dir, "out.csv"))
+    if !strings.HasPrefix(string(content), "FUZZ,url,") || !strings.Contains(string(content), "\"a,\"\"b\"\"\",http://localhost/a,") {
+        t.Errorf("Unexpected csv content %q", content)
+    }
+}
@@ -7,6 +7,8 @@ import (
     "os"
     "path"
     "strconv"
+    "strings"
+    "sync"
     "time"
 
     "github.com/theblackturtle/ffuf/pkg/ffuf"
@@ -25,27 +27,72 @@ const (
 )
 
 type Stdoutput struct {
-    config  *ffuf.Config
-    Results []Result
+    config      *ffuf.Config
+    mu          sync.Mutex
+    Results     []Result
+    streams     map[string]streamWriter
+    keepResults bool
+    dedup       map[string]*dedupGroup
+    duplicates  int
 }
 
 type Result struct {
-    Input            map[string][]byte `json:"input"`
-    Position         int               `json:"position"`
-    StatusCode       int64             `json:"status"`
-    ContentLength    int64             `json:"length"`
-    ContentWords     int64             `json:"words"`
-    ContentLines     int64             `json:"lines"`
-    RedirectLocation string            `json:"redirectlocation"`
-    Url              string            `json:"url"`
-    ResultFile       string            `json:"resultfile"`
-    HTMLColor        string            `json:"-"`
+    Input            map[string][]byte   `json:"input"`
+    Position         int                 `json:"position"`
+    Active           string              `json:"active_keyword,omitempty"`
+    StatusCode       int64               `json:"status"`
+    ContentLength    int64               `json:"length"`
+    ContentWords     int64               `json:"words"`
+    ContentLines     int64               `json:"lines"`
+    RedirectLocation string              `json:"redirectlocation"`
+    Url              string              `json:"url"`
+    ResultFile       string              `json:"resultfile"`
+    Duration         int64               `json:"duration"`
+    TimeToFirstByte  int64               `json:"ttfb"`
+    Reflected        bool                `json:"reflected"`
+    Reflections      []ffuf.Reflection   `json:"reflections,omitempty"`
+    Duplicates       int                 `json:"duplicates,omitempty"`
+    DuplicateInputs  []map[string][]byte `json:"duplicate_inputs,omitempty"`
+    ErrorClass       string              `json:"error_class,omitempty"`
+    Error            string              `json:"error,omitempty"`
+    HTMLColor        string              `json:"-"`
+    dedupKey         string
+}
+
+// dedupGroup holds the inputs of the responses collapsed into the first response with the same hash
+type dedupGroup struct {
+    inputs []map[string][]byte
 }
 
 func NewStdoutput(conf *ffuf.Config) *Stdoutput {
     var outp Stdoutput
     outp.config = conf
     outp.Results = []Result{}
+    outp.streams = make(map[string]streamWriter)
+    outp.dedup = make(map[string]*dedupGroup)
+    if conf.OutputFile != "" {
+        for _, format := range conf.OutputFormats {
+            var stream streamWriter
+            var err error
+            filename := outputFilename(conf, format)
+            switch format {
+            case "jsonl":
+                stream, err = newJSONLWriter(conf, filename)
+            case "csv":
+                stream, err = newCSVWriter(conf, filename, false)
+            case "ecsv":
+                stream, err = newCSVWriter(conf, filename, true)
+            default:
+                outp.keepResults = true
+                continue
+            }
+            if err != nil {
+                outp.Error(fmt.Sprintf("%s", err))
+                continue
+            }
+            outp.streams[format] = stream
+        }
+    }
     return &outp
 }
 
@@ -75,8 +122,23 @@ func (s *Stdoutput) Banner() error {
 
     // Output file info
     if len(s.config.OutputFile) > 0 {
-        printOption([]byte("Output file"), []byte(s.config.OutputFile))
-        printOption([]byte("File format"), []byte(s.config.OutputFormat))
+        if len(s.config.OutputFormats) > 1 {
+            for _, format := range s.config.OutputFormats {
+                printOption([]byte("Output file"), []byte(fmt.Sprintf("%s (%s)", outputFilename(s.config, format), format)))
+            }
+        } else {
+            printOption([]byte("Output file"), []byte(s.config.OutputFile))
+            printOption([]byte("File format"), []byte(strings.Join(s.config.OutputFormats, "")))
+        }
+    }
+
+    // Runner
+    if s.config.Runner != "http" {
+        runner := s.config.Runner
+        if s.config.H2C {
+            runner += " (h2c prior knowledge)"
+        }
+        printOption([]byte("Runner"), []byte(runner))
     }
 
     // Follow redirects?
@@ -95,16 +157,53 @@ func (s *Stdoutput) Banner() error {
     if len(s.config.ReplayProxyURL) > 0 {
         replayproxy := fmt.Sprintf("%s", s.config.ReplayProxyURL)
         printOption([]byte("ReplayProxy"), []byte(replayproxy))
+        if len(s.config.ReplayMarker) > 0 {
+            printOption([]byte("Replay marker"), []byte(s.config.ReplayMarker))
+        }
     }
 
     // Timeout
     timeout := fmt.Sprintf("%d", s.config.Timeout)
     printOption([]byte("Timeout"), []byte(timeout))
 
+    // Retries
+    if s.config.Retries != 1 || len(s.config.RetryStatuses) > 0 || len(s.config.RetryErrors) != len(ffuf.ErrorClasses) {
+        retries := fmt.Sprintf("%d", s.config.Retries)
+        if s.config.Retries > 0 {
+            retryOn := make([]string, 0)
+            if len(s.config.RetryErrors) == len(ffuf.ErrorClasses) {
+                retryOn = append(retryOn, "errors")
+            } else {
+                retryOn = append(retryOn, s.config.RetryErrors...)
+            }
+            for _, vr := range s.config.RetryStatuses {
+                if vr.Min == vr.Max {
+                    retryOn = append(retryOn, fmt.Sprintf("%d", vr.Min))
+                } else {
+                    retryOn = append(retryOn, fmt.Sprintf("%d-%d", vr.Min, vr.Max))
+                }
+            }
+            retries = fmt.Sprintf("%s (on %s, delay %.2fs)", retries, strings.Join(retryOn, ","), s.config.RetryDelay)
+        }
+        printOption([]byte("Retries"), []byte(retries))
+    }
+
     // Threads
     threads := fmt.Sprintf("%d", s.config.Threads)
     printOption([]byte("Threads"), []byte(threads))
 
+    // Rate limit?
+    if s.config.Rate > 0 {
+        rate := fmt.Sprintf("%d req/sec", s.config.Rate)
+        if s.config.RatePerHost {
+            rate += " per host"
+        }
+        printOption([]byte("Rate limit"), []byte(rate))
+    }
+    if s.config.AdaptiveThrottle {
+        printOption([]byte("Adaptive"), []byte("true"))
+    }
+
     // Delay?
     if s.config.Delay.HasDelay {
         delay := ""
@@ -117,13 +216,22 @@ func (s *Stdoutput) Banner() error {
     }
 
     // Print matchers
+    if len(s.config.Matchers) > 1 {
+        printOption([]byte("Matcher mode"), []byte(s.config.MatcherMode))
+    }
     for _, f := range s.config.Matchers {
         printOption([]byte("Matcher"), []byte(f.Repr()))
     }
     // Print filters
+    if len(s.config.Filters) > 1 {
+        printOption([]byte("Filter mode"), []byte(s.config.FilterMode))
+    }
     for _, f := range s.config.Filters {
         printOption([]byte("Filter"), []byte(f.Repr()))
     }
+    for _, f := range s.config.CalibrationFilters {
+        printOption([]byte("Auto filter"), []byte(f.Repr()))
+    }
     fmt.Printf("%s\n\n", BANNER_SEP)
     return nil
 }
@@ -149,7 +257,30 @@ func (s *Stdoutput) Progress(status ffuf.Progress) {
     dur -= mins * time.Minute
     secs := dur / time.Second
 
-    fmt.Fprintf(os.Stderr, "%s:: Progress: [%d/%d] :: Job [%d/%d] :: %d req/sec :: Duration: [%d:%02d:%02d] :: Errors: %d ::", TERMINAL_CLEAR_LINE, status.ReqCount, status.ReqTotal, status.QueuePos, status.QueueTotal, reqRate, hours, mins, secs, status.ErrorCount)
+    rateLimit := ""
+    if status.RateLimit > 0 {
+        rateLimit = fmt.Sprintf(" (limit %d)", status.RateLimit)
+    }
+    if status.ThreadLimit > 0 && status.ThreadLimit < s.config.Threads {
+        rateLimit = fmt.Sprintf("%s :: Threads: %d/%d", rateLimit, status.ThreadLimit, s.config.Threads)
+    }
+
+    errorClasses := ""
+    if status.ErrorCount > 0 {
+        classes := make([]string, 0)
+        for _, class := range ffuf.ErrorClasses {
+            if count := status.ErrorClass[class]; count > 0 {
+                classes = append(classes, fmt.Sprintf("%s: %d", class, count))
+            }
+        }
+        errorClasses = fmt.Sprintf(" (%s)", strings.Join(classes, ", "))
+    }
+
+    reqTotal := strconv.Itoa(status.ReqTotal)
+    if status.ReqTotal < 0 {
+        reqTotal = "?"
+    }
+    fmt.Fprintf(os.Stderr, "%s:: Progress: [%d/%s] :: Job [%d/%d] :: %d req/sec%s :: Duration: [%d:%02d:%02d] :: Errors: %d%s ::", TERMINAL_CLEAR_LINE, status.ReqCount, reqTotal, status.QueuePos, status.QueueTotal, reqRate, rateLimit, hours, mins, secs, status.ErrorCount, errorClasses)
 }
 
 func (s *Stdoutput) Info(infostring string) {
@@ -190,22 +321,27 @@ func (s *Stdoutput) Warning(warnstring string) {
 
 func (s *Stdoutput) Finalize() error {
     var err error
+    if s.config.Dedup && s.duplicates > 0 {
+        s.Info(fmt.Sprintf("Collapsed %d duplicate responses into %d unique ones", s.duplicates, len(s.dedup)))
+    }
     if s.config.OutputFile != "" {
-        if s.config.OutputFormat == "json" {
-            err = writeJSON(s.config, s.Results)
-        } else if s.config.OutputFormat == "ejson" {
-            err = writeEJSON(s.config, s.Results)
-        } else if s.config.OutputFormat == "html" {
-            err = writeHTML(s.config, s.Results)
-        } else if s.config.OutputFormat == "md" {
-            err = writeMarkdown(s.config, s.Results)
-        } else if s.config.OutputFormat == "csv" {
-            err = writeCSV(s.config, s.Results, false)
-        } else if s.config.OutputFormat == "ecsv" {
-            err = writeCSV(s.config, s.Results, true)
-        }
-        if err != nil {
-            s.Error(fmt.Sprintf("%s", err))
+        s.groupDuplicates()
+        for _, format := range s.config.OutputFormats {
+            filename := outputFilename(s.config, format)
+            if format == "json" {
+                err = writeJSON(s.config, filename, s.Results)
+            } else if format == "ejson" {
+                err = writeEJSON(s.config, filename, s.Results)
+            } else if format == "html" {
+                err = writeHTML(s.config, filename, s.Results)
+            } else if format == "md" {
+                err = writeMarkdown(s.config, filename, s.Results)
+            } else if stream, ok := s.streams[format]; ok {
+                err = stream.Close()
+            }
+            if err != nil {
+                s.Error(fmt.Sprintf("%s", err))
+            }
         }
     }
     fmt.Fprintf(os.Stderr, "\n")
@@ -213,10 +349,20 @@ func (s *Stdoutput) Finalize() error {
 }
 
 func (s *Stdoutput) Result(resp ffuf.Response) {
+    dedupKey := ""
+    if s.config.Dedup {
+        dedupKey = ffuf.ResponseHash(&resp)
+        if s.collapse(dedupKey, resp) {
+            return
+        }
+    }
     // Do we want to write request and response to a file
     if len(s.config.OutputDirectory) > 0 {
         resp.ResultFile = s.writeResultToFile(resp)
     }
+    if s.config.Verbose || s.config.OutputFile != "" {
+        resp.GetReflections()
+    }
     // Output the result
     s.printResult(resp)
     // Check if we need the data later
@@ -229,6 +375,7 @@ func (s *Stdoutput) Result(resp ffuf.Response) {
         sResult := Result{
             Input:            inputs,
             Position:         resp.Request.Position,
+            Active:           resp.Request.Active,
             StatusCode:       resp.StatusCode,
             ContentLength:    resp.ContentLength,
             ContentWords:     resp.ContentWords,
@@ -236,8 +383,78 @@ func (s *Stdoutput) Result(resp ffuf.Response) {
             RedirectLocation: resp.GetRedirectLocation(false),
             Url:              resp.Request.Url,
             ResultFile:       resp.ResultFile,
+            Duration:         resp.Duration.Milliseconds(),
+            TimeToFirstByte:  resp.TimeToFirstByte.Milliseconds(),
         }
-        s.Results = append(s.Results, sResult)
+        sResult.Reflections = resp.Reflections
+        sResult.Reflected = len(sResult.Reflections) > 0
+        sResult.dedupKey = dedupKey
+        s.storeResult(sResult)
+    }
+}
+
+// ErrorResult records a request that failed to get a response, so it ends up in the output file
+func (s *Stdoutput) ErrorResult(req ffuf.Request, errclass string, err error) {
+    if s.config.OutputFile == "" || !s.config.OutputErrors {
+        return
+    }
+    inputs := make(map[string][]byte, 0)
+    for k, v := range req.Input {
+        inputs[k] = v
+    }
+    sResult := Result{
+        Input:      inputs,
+        Position:   req.Position,
+        Active:     req.Active,
+        Url:        req.Url,
+        ErrorClass: errclass,
+        Error:      fmt.Sprintf("%s", err),
+    }
+    s.storeResult(sResult)
+}
+
+// collapse records the response as a duplicate if a response with the same hash was already seen,
+// and returns true if it was one
+func (s *Stdoutput) collapse(key string, resp ffuf.Response) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    group, ok := s.dedup[key]
+    if !ok {
+        s.dedup[key] = &dedupGroup{inputs: make([]map[string][]byte, 0)}
+        return false
+    }
+    inputs := make(map[string][]byte, 0)
+    for k, v := range resp.Request.Input {
+        inputs[k] = v
+    }
+    group.inputs = append(group.inputs, inputs)
+    s.duplicates++
+    return true
+}
+
+// groupDuplicates adds the inputs collapsed into the results to them
+func (s *Stdoutput) groupDuplicates() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for i, r := range s.Results {
+        if group, ok := s.dedup[r.dedupKey]; ok && r.dedupKey != "" {
+            s.Results[i].Duplicates = len(group.inputs)
+            s.Results[i].DuplicateInputs = group.inputs
+        }
+    }
+}
+
+// storeResult writes the result right away to the streaming formats, and keeps it if a format written at Finalize needs it
+func (s *Stdoutput) storeResult(res Result) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for _, stream := range s.streams {
+        if err := stream.Write(res); err != nil {
+            s.Error(fmt.Sprintf("%s", err))
+        }
+    }
+    if s.keepResults {
+        s.Results = append(s.Results, res)
     }
 }
 
@@ -308,11 +525,14 @@ func (s *Stdoutput) resultQuiet(resp ffuf.Response) {
 func (s *Stdoutput) resultMultiline(resp ffuf.Response) {
     var res_hdr, res_str string
     res_str = "%s%s    * %s: %s\n"
-    res_hdr = fmt.Sprintf("%s[Status: %d, Size: %d, Words: %d, Lines: %d]", TERMINAL_CLEAR_LINE, resp.StatusCode, resp.ContentLength, resp.ContentWords, resp.ContentLines)
+    res_hdr = fmt.Sprintf("%s[Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms, TTFB: %dms]", TERMINAL_CLEAR_LINE, resp.StatusCode, resp.ContentLength, resp.ContentWords, resp.ContentLines, resp.Duration.Milliseconds(), resp.TimeToFirstByte.Milliseconds())
     res_hdr = s.colorize(res_hdr, resp.StatusCode)
     reslines := ""
     if s.config.Verbose {
         reslines = fmt.Sprintf("%s%s| URL | %s\n", reslines, TERMINAL_CLEAR_LINE, resp.Request.Url)
+        for _, r := range resp.Reflections {
+            reslines = fmt.Sprintf("%s%s| RFL | %s (%s) in %s, %s\n", reslines, TERMINAL_CLEAR_LINE, r.Keyword, r.Encoding, r.Location, r.Context)
+        }
         redirectLocation := resp.GetRedirectLocation(false)
         if redirectLocation != "" {
             reslines = fmt.Sprintf("%s%s| --> | %s\n", reslines, TERMINAL_CLEAR_LINE, redirectLocation)
@@ -321,6 +541,9 @@ func (s *Stdoutput) resultMultiline(resp ffuf.Response) {
     if resp.ResultFile != "" {
         reslines = fmt.Sprintf("%s%s| RES | %s\n", reslines, TERMINAL_CLEAR_LINE, resp.ResultFile)
     }
+    if resp.Request.Active != "" {
+        reslines = fmt.Sprintf("%s%s| KEY | %s\n", reslines, TERMINAL_CLEAR_LINE, resp.Request.Active)
+    }
     for k, v := range resp.Request.Input {
         if inSlice(k, s.config.CommandKeywords) {
             // If we're using external command for input, display the position instead of input
@@ -335,7 +558,7 @@ func (s *Stdoutput) resultMultiline(resp ffuf.Response) {
 
 func (s *Stdoutput) resultNormal(resp ffuf.Response) {
     var res_str string
-    res_str = fmt.Sprintf("%s%-23s [Status: %s, Size: %d, Words: %d, Lines: %d]", TERMINAL_CLEAR_LINE, s.prepareInputsOneLine(resp), s.colorize(fmt.Sprintf("%d", resp.StatusCode), resp.StatusCode), resp.ContentLength, resp.ContentWords, resp.ContentLines)
+    res_str = fmt.Sprintf("%s%-23s [Status: %s, Size: %d, Words: %d, Lines: %d, Duration: %dms, TTFB: %dms]", TERMINAL_CLEAR_LINE, s.prepareInputsOneLine(resp), s.colorize(fmt.Sprintf("%d", resp.StatusCode), resp.StatusCode), resp.ContentLength, resp.ContentWords, resp.ContentLines, resp.Duration.Milliseconds(), resp.TimeToFirstByte.Milliseconds())
     fmt.Println(res_str)
 }
 
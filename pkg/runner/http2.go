@@ -0,0 +1,267 @@
+package runner
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "log"
+    "net"
+    "net/http"
+    "net/http/httptrace"
+    "sort"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+    "golang.org/x/net/http2"
+)
+
+// HTTP2Runner is a net/http based runner negotiating HTTP/2 using ALPN, falling back to HTTP/1.1.
+// With Config.H2C the cleartext targets are spoken to with HTTP/2 prior knowledge.
+type HTTP2Runner struct {
+    config *ffuf.Config
+    client *http.Client
+    replay bool
+    marker []string
+}
+
+func NewHTTP2Runner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
+    var h2runner HTTP2Runner
+    h2runner.config = conf
+    h2runner.replay = replay
+    h2runner.marker = replayMarker(conf, replay)
+
+    dialer := &net.Dialer{Timeout: 30 * time.Second}
+    dial := dialer.DialContext
+    proxyURL := runnerProxyURL(conf, replay)
+    if len(proxyURL) > 0 {
+        proxy, err := newUpstreamProxy(proxyURL, 30*time.Second)
+        if err != nil {
+            // Never fall back to a direct connection when a proxy was requested
+            dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+                return nil, fmt.Errorf("invalid proxy url %s: %s", proxyURL, err)
+            }
+        } else {
+            // Every target is tunnelled, so the protocol gets negotiated end to end
+            dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+                return proxy.DialContext(ctx, addr)
+            }
+        }
+    }
+
+    tlsConfig := &tls.Config{
+        InsecureSkipVerify: true,
+        Renegotiation:      tls.RenegotiateOnceAsClient,
+    }
+    var transport http.RoundTripper
+    if conf.H2C {
+        transport = newH2CTransport(conf, dial, tlsConfig)
+    } else {
+        h1 := &http.Transport{
+            DialContext:         dial,
+            DisableCompression:  true,
+            MaxIdleConnsPerHost: conf.Threads,
+            TLSHandshakeTimeout: time.Duration(conf.Timeout) * time.Second,
+            TLSClientConfig:     tlsConfig,
+        }
+        // Offer h2 in the ALPN, falling back to HTTP/1.1
+        if err := http2.ConfigureTransport(h1); err != nil {
+            log.Printf("Could not enable HTTP/2, using HTTP/1.1: %s", err)
+        }
+        transport = h1
+    }
+
+    h2runner.client = &http.Client{
+        Transport: transport,
+        Timeout:   time.Duration(conf.Timeout) * time.Second,
+        CheckRedirect: func(req *http.Request, via []*http.Request) error {
+            if !conf.FollowRedirects || replay {
+                return http.ErrUseLastResponse
+            }
+            if len(via) > MaxRedirectTimes {
+                return errors.New("too many redirects")
+            }
+            return nil
+        },
+    }
+
+    return &h2runner
+}
+
+// h2cTransport speaks HTTP/2 with prior knowledge to the cleartext targets, and HTTP/2 over TLS
+// to the others
+type h2cTransport struct {
+    cleartext *http2.Transport
+    tls       *http2.Transport
+}
+
+func newH2CTransport(conf *ffuf.Config, dial func(ctx context.Context, network, addr string) (net.Conn, error), tlsConfig *tls.Config) *h2cTransport {
+    // http2.Transport does not pass the request context to the dial function, so the dials are
+    // cancelled with the context of the whole run, and time out with the dialer or the proxy
+    ctx := conf.Context
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    return &h2cTransport{
+        cleartext: &http2.Transport{
+            AllowHTTP:          true,
+            DisableCompression: true,
+            DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+                return dial(ctx, network, addr)
+            },
+        },
+        tls: &http2.Transport{
+            DisableCompression: true,
+            TLSClientConfig:    tlsConfig,
+            DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+                conn, err := dial(ctx, network, addr)
+                if err != nil {
+                    return nil, err
+                }
+                tlsConn := tls.Client(conn, cfg)
+                conn.SetDeadline(time.Now().Add(time.Duration(conf.Timeout) * time.Second))
+                stop := closeOnDone(ctx, conn)
+                err = tlsConn.Handshake()
+                stop()
+                if err != nil {
+                    conn.Close()
+                    return nil, contextError(ctx, err)
+                }
+                conn.SetDeadline(time.Time{})
+                return tlsConn, nil
+            },
+        },
+    }
+}
+
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    if req.URL.Scheme == "http" {
+        return t.cleartext.RoundTrip(req)
+    }
+    return t.tls.RoundTrip(req)
+}
+
+func (r *HTTP2Runner) Prepare(input map[string][]byte) (ffuf.Request, error) {
+    return prepareRequest(r.config, input), nil
+}
+
+func (r *HTTP2Runner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+    httpreq, err := http.NewRequest(req.Method, req.Url, bytes.NewReader(req.Data))
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    httpreq.Header.Set("Accept", "*/*")
+    httpreq.Header.Set("Accept-Language", "en-US,en;q=0.8")
+
+    for key, value := range req.Headers {
+        httpreq.Header.Set(key, value)
+    }
+    // set default User-Agent header if not present
+    if _, ok := req.Headers["User-Agent"]; !ok {
+        httpreq.Header.Set("User-Agent", DefaultUserAgent)
+    }
+
+    // Tag the replayed requests so they can be found from the proxy history
+    if r.replay && len(r.marker) == 2 {
+        httpreq.Header.Set(r.marker[0], r.marker[1])
+    }
+
+    // Handle Go http.Request special cases
+    if _, ok := req.Headers["Host"]; ok {
+        httpreq.Host = req.Headers["Host"]
+    }
+    req.Host = httpreq.Host
+    if r.config.Context != nil {
+        httpreq = httpreq.WithContext(r.config.Context)
+    }
+    start := time.Now()
+    var firstByte time.Time
+    trace := &httptrace.ClientTrace{
+        GotFirstResponseByte: func() {
+            // Redirects are followed, keep the first byte of the last response
+            firstByte = time.Now()
+        },
+    }
+    httpreq = httpreq.WithContext(httptrace.WithClientTrace(httpreq.Context(), trace))
+
+    httpresp, err := r.client.Do(httpreq)
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    defer httpresp.Body.Close()
+
+    resp := ffuf.NewHTTPResponse(httpresp, req)
+    resp.TimeToFirstByte = timeToFirstByte(start, firstByte)
+    resp.Duration = time.Since(start)
+    if r.replay {
+        // The response of a replayed request is only interesting to the proxy
+        return resp, nil
+    }
+    // Check if we should download the resource or not
+    if httpresp.ContentLength >= 0 {
+        resp.ContentLength = httpresp.ContentLength
+        if (r.config.IgnoreBody) || (httpresp.ContentLength > MAX_DOWNLOAD_SIZE) {
+            resp.Cancelled = true
+            return resp, nil
+        }
+    }
+
+    rawBody, err := ioutil.ReadAll(io.LimitReader(httpresp.Body, MAX_DOWNLOAD_SIZE+1))
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    resp.Duration = time.Since(start)
+    if len(rawBody) > MAX_DOWNLOAD_SIZE {
+        resp.Cancelled = true
+        return resp, nil
+    }
+    respBody, err := decodeBody(httpresp.Header.Get("Content-Encoding"), rawBody)
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    setResponseBody(&resp, respBody)
+
+    statusFormat := fmt.Sprintf("Status: %d, Size: %d, Words: %d, Lines: %d", resp.StatusCode, resp.ContentLength, resp.ContentWords, resp.ContentLines)
+    if len(r.config.OutputDirectory) > 0 {
+        resp.Request.Raw = dumpHTTPRequest(httpresp.Request, statusFormat)
+        resp.Raw = dumpHTTPResponse(httpresp, string(respBody))
+    }
+
+    return resp, nil
+}
+
+func dumpHTTPRequest(req *http.Request, statusFormat string) string {
+    buf := &bytes.Buffer{}
+    buf.WriteString(req.URL.String())
+    buf.WriteString("\n\n")
+    buf.WriteString(statusFormat)
+    buf.WriteString("\n\n")
+    buf.WriteString(fmt.Sprintf("> Host: %s\n", req.Host))
+    writeHTTPHeaders(buf, "> ", req.Header)
+    return buf.String()
+}
+
+func dumpHTTPResponse(resp *http.Response, body string) string {
+    buf := &bytes.Buffer{}
+    buf.WriteString(fmt.Sprintf("< %s %d\n", resp.Proto, resp.StatusCode))
+    writeHTTPHeaders(buf, "< ", resp.Header)
+    buf.WriteString("\n")
+    buf.WriteString(body)
+    return buf.String()
+}
+
+func writeHTTPHeaders(buf *bytes.Buffer, prefix string, headers http.Header) {
+    keys := make([]string, 0, len(headers))
+    for k := range headers {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        for _, v := range headers[k] {
+            buf.WriteString(fmt.Sprintf("%s%s: %s\n", prefix, k, v))
+        }
+    }
+}
@@ -0,0 +1,165 @@
+package runner
+
+import (
+    "context"
+    "crypto/tls"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+    "golang.org/x/net/http2"
+    "golang.org/x/net/http2/h2c"
+)
+
+const http2TestDelay = 50 * time.Millisecond
+
+// http2TestHandler answers after a delay, sending the protocol of the request to the channel.
+// /redirect redirects to /final.
+func http2TestHandler(protos chan string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        protos <- r.Proto
+        if r.URL.Path == "/redirect" {
+            http.Redirect(w, r, "/final", http.StatusFound)
+            return
+        }
+        time.Sleep(http2TestDelay)
+        w.Header().Set("Content-Type", "text/plain")
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte("found it at " + r.URL.Path + "\nsecond line\n"))
+    }
+}
+
+// newHTTP2TestServer starts a TLS server offering h2 and HTTP/1.1 in the ALPN, or only HTTP/1.1
+func newHTTP2TestServer(protos chan string, h2 bool) *httptest.Server {
+    srv := httptest.NewUnstartedServer(http2TestHandler(protos))
+    if h2 {
+        // httptest.Server.EnableHTTP2 needs Go 1.14
+        http2.ConfigureServer(srv.Config, nil)
+        srv.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+    }
+    srv.StartTLS()
+    return srv
+}
+
+func executeHTTP2(t *testing.T, conf *ffuf.Config, url string) ffuf.Response {
+    runner := NewHTTP2Runner(conf, false)
+    resp, err := runner.Execute(&ffuf.Request{Method: "GET", Url: url})
+    if err != nil {
+        t.Fatalf("%s: unexpected error %s", url, err)
+    }
+    return resp
+}
+
+func TestHTTP2RunnerProtocols(t *testing.T) {
+    protos := make(chan string, 10)
+    h2srv := newHTTP2TestServer(protos, true)
+    defer h2srv.Close()
+    h1srv := newHTTP2TestServer(protos, false)
+    defer h1srv.Close()
+    h2csrv := httptest.NewServer(h2c.NewHandler(http2TestHandler(protos), &http2.Server{}))
+    defer h2csrv.Close()
+
+    for _, test := range []struct {
+        name      string
+        url       string
+        h2c       bool
+        wantProto string
+    }{
+        {name: "alpn", url: h2srv.URL, wantProto: "HTTP/2.0"},
+        {name: "http/1.1 fallback", url: h1srv.URL, wantProto: "HTTP/1.1"},
+        {name: "h2c prior knowledge", url: h2csrv.URL, h2c: true, wantProto: "HTTP/2.0"},
+        {name: "h2c over tls", url: h2srv.URL, h2c: true, wantProto: "HTTP/2.0"},
+        {name: "cleartext without h2c", url: h2csrv.URL, wantProto: "HTTP/1.1"},
+    } {
+        conf := ffuf.NewConfig(context.Background())
+        conf.H2C = test.h2c
+        resp := executeHTTP2(t, &conf, test.url+"/path")
+        if proto := <-protos; proto != test.wantProto {
+            t.Errorf("%s: expected the request over %s, got %s", test.name, test.wantProto, proto)
+        }
+        if resp.StatusCode != 200 || string(resp.Data) != "found it at /path\nsecond line\n" {
+            t.Errorf("%s: unexpected response %d %q", test.name, resp.StatusCode, resp.Data)
+        }
+    }
+}
+
+func TestHTTP2RunnerMatchesSimpleRunner(t *testing.T) {
+    protos := make(chan string, 10)
+    srv := newHTTP2TestServer(protos, true)
+    defer srv.Close()
+    conf := ffuf.NewConfig(context.Background())
+
+    h2resp := executeHTTP2(t, &conf, srv.URL+"/path")
+    simpleresp, err := NewSimpleRunner(&conf, false).Execute(&ffuf.Request{Method: "GET", Url: srv.URL + "/path"})
+    if err != nil {
+        t.Fatalf("Unexpected error %s", err)
+    }
+    if <-protos != "HTTP/2.0" || <-protos != "HTTP/1.1" {
+        t.Fatalf("Expected a HTTP/2 and a HTTP/1.1 request")
+    }
+    if h2resp.StatusCode != simpleresp.StatusCode || h2resp.ContentLength != simpleresp.ContentLength ||
+        h2resp.ContentWords != simpleresp.ContentWords || h2resp.ContentLines != simpleresp.ContentLines {
+        t.Errorf("Expected the status, size, words and lines %d %d %d %d, got %d %d %d %d", simpleresp.StatusCode,
+            simpleresp.ContentLength, simpleresp.ContentWords, simpleresp.ContentLines,
+            h2resp.StatusCode, h2resp.ContentLength, h2resp.ContentWords, h2resp.ContentLines)
+    }
+    for name, resp := range map[string]ffuf.Response{"http2": h2resp, "simple": simpleresp} {
+        if resp.TimeToFirstByte < http2TestDelay || resp.TimeToFirstByte > resp.Duration {
+            t.Errorf("%s: expected the time to first byte to be between %s and the duration %s, got %s", name, http2TestDelay, resp.Duration, resp.TimeToFirstByte)
+        }
+    }
+}
+
+func TestHTTP2RunnerRedirects(t *testing.T) {
+    protos := make(chan string, 10)
+    srv := newHTTP2TestServer(protos, true)
+    defer srv.Close()
+    for _, follow := range []bool{false, true} {
+        conf := ffuf.NewConfig(context.Background())
+        conf.FollowRedirects = follow
+        resp := executeHTTP2(t, &conf, srv.URL+"/redirect")
+        if follow {
+            <-protos
+            if resp.StatusCode != 200 || string(resp.Data) != "found it at /final\nsecond line\n" {
+                t.Errorf("Expected the redirect to be followed, got %d %q", resp.StatusCode, resp.Data)
+            }
+        } else if resp.StatusCode != 302 || resp.GetRedirectLocation(false) != "/final" {
+            t.Errorf("Expected the redirect response, got %d to %q", resp.StatusCode, resp.GetRedirectLocation(false))
+        }
+        <-protos
+    }
+}
+
+func TestHTTP2RunnerProxy(t *testing.T) {
+    protos := make(chan string, 10)
+    h2srv := newHTTP2TestServer(protos, true)
+    defer h2srv.Close()
+    h2csrv := httptest.NewServer(h2c.NewHandler(http2TestHandler(protos), &http2.Server{}))
+    defer h2csrv.Close()
+    proxy, tunnels := startForwardProxy(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusMethodNotAllowed)
+    })
+    defer proxy.Close()
+
+    // Every target is tunnelled, so HTTP/2 is spoken end to end
+    for _, test := range []struct {
+        srv *httptest.Server
+        h2c bool
+    }{
+        {srv: h2srv},
+        {srv: h2csrv, h2c: true},
+    } {
+        conf := ffuf.NewConfig(context.Background())
+        conf.ProxyURL = proxy.URL
+        conf.H2C = test.h2c
+        resp := executeHTTP2(t, &conf, test.srv.URL+"/path")
+        if tunnel := <-tunnels; tunnel != test.srv.Listener.Addr().String() {
+            t.Errorf("%s: expected a tunnel to the target, got %s", test.srv.URL, tunnel)
+        }
+        if proto := <-protos; proto != "HTTP/2.0" || resp.StatusCode != 200 {
+            t.Errorf("%s: expected a HTTP/2 request through the tunnel, got %s and status %d", test.srv.URL, proto, resp.StatusCode)
+        }
+    }
+}
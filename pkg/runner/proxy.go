@@ -0,0 +1,319 @@
+package runner
+
+import (
+    "bufio"
+    "context"
+    "crypto/tls"
+    "encoding/base64"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "strconv"
+    "time"
+
+    "github.com/valyala/fasthttp"
+)
+
+const (
+    socks5Version       = 0x05
+    socks5AuthNone      = 0x00
+    socks5AuthPassword  = 0x02
+    socks5AuthNoMethods = 0xff
+    socks5CmdConnect    = 0x01
+    socks5AtypIPv4      = 0x01
+    socks5AtypDomain    = 0x03
+    socks5AtypIPv6      = 0x04
+)
+
+// upstreamProxy holds a parsed upstream proxy definition
+type upstreamProxy struct {
+    url     *url.URL
+    addr    string
+    auth    string
+    timeout time.Duration
+}
+
+// newUpstreamProxy parses the proxy URL. Supported schemes are http, https, socks5 and socks5h.
+func newUpstreamProxy(proxyURL string, timeout time.Duration) (*upstreamProxy, error) {
+    u, err := url.Parse(proxyURL)
+    if err != nil {
+        return nil, err
+    }
+    if len(u.Hostname()) == 0 {
+        return nil, fmt.Errorf("proxy host missing")
+    }
+    var defaultPort string
+    switch u.Scheme {
+    case "http":
+        defaultPort = "80"
+    case "https":
+        defaultPort = "443"
+    case "socks5", "socks5h":
+        defaultPort = "1080"
+    default:
+        return nil, fmt.Errorf("unsupported proxy scheme: %s", u.Scheme)
+    }
+    p := &upstreamProxy{url: u, timeout: timeout}
+    p.addr = u.Host
+    if len(u.Port()) == 0 {
+        p.addr = net.JoinHostPort(u.Hostname(), defaultPort)
+    }
+    if u.User != nil {
+        pass, _ := u.User.Password()
+        p.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+pass))
+    }
+    return p, nil
+}
+
+// isHTTP returns true for proxies speaking the HTTP protocol
+func (p *upstreamProxy) isHTTP() bool {
+    return p.url.Scheme == "http" || p.url.Scheme == "https"
+}
+
+// dialProxy opens a connection to the proxy itself, wrapping it in TLS for https proxies
+func (p *upstreamProxy) dialProxy() (net.Conn, error) {
+    return p.dialProxyContext(context.Background())
+}
+
+// dialProxyContext opens a connection to the proxy, giving up when the context is done
+func (p *upstreamProxy) dialProxyContext(ctx context.Context) (net.Conn, error) {
+    dialer := &net.Dialer{Timeout: p.timeout, DualStack: true}
+    conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+    if err != nil {
+        return nil, err
+    }
+    if p.url.Scheme == "https" {
+        tlsConn := tls.Client(conn, &tls.Config{
+            ServerName:         p.url.Hostname(),
+            InsecureSkipVerify: true,
+        })
+        conn.SetDeadline(p.deadline(ctx))
+        stop := closeOnDone(ctx, conn)
+        err := tlsConn.Handshake()
+        stop()
+        if err != nil {
+            conn.Close()
+            return nil, contextError(ctx, err)
+        }
+        conn.SetDeadline(time.Time{})
+        return tlsConn, nil
+    }
+    return conn, nil
+}
+
+// Dial returns a connection to addr tunnelled through the proxy
+func (p *upstreamProxy) Dial(addr string) (net.Conn, error) {
+    return p.DialContext(context.Background(), addr)
+}
+
+// DialContext returns a connection to addr tunnelled through the proxy. The connection is closed
+// if the context is done before the proxy handshake is.
+func (p *upstreamProxy) DialContext(ctx context.Context, addr string) (net.Conn, error) {
+    conn, err := p.dialProxyContext(ctx)
+    if err != nil {
+        return nil, err
+    }
+    conn.SetDeadline(p.deadline(ctx))
+    stop := closeOnDone(ctx, conn)
+    tunnel := conn
+    if p.isHTTP() {
+        tunnel, err = p.connect(conn, addr)
+    } else {
+        err = p.socks5(conn, addr)
+    }
+    stop()
+    if err != nil {
+        conn.Close()
+        return nil, fmt.Errorf("proxy %s: %s", p.addr, contextError(ctx, err))
+    }
+    conn.SetDeadline(time.Time{})
+    return tunnel, nil
+}
+
+// deadline returns the deadline of the proxy handshake, the proxy timeout or the deadline of the
+// context, whichever is earlier
+func (p *upstreamProxy) deadline(ctx context.Context) time.Time {
+    deadline := time.Now().Add(p.timeout)
+    if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+        return d
+    }
+    return deadline
+}
+
+// closeOnDone closes the connection if the context is done before the returned function is called
+func closeOnDone(ctx context.Context, conn net.Conn) func() {
+    done := make(chan struct{})
+    go func() {
+        select {
+        case <-ctx.Done():
+            conn.Close()
+        case <-done:
+        }
+    }()
+    return func() { close(done) }
+}
+
+// contextError returns the error of the context if it is done, as it caused the error. The
+// connection deadline can be the deadline of the context, and may pass just before the context is.
+func contextError(ctx context.Context, err error) error {
+    if ctx.Err() != nil {
+        return ctx.Err()
+    }
+    if d, ok := ctx.Deadline(); ok && !time.Now().Before(d) {
+        return context.DeadlineExceeded
+    }
+    return err
+}
+
+// connect opens a tunnel using the HTTP CONNECT method
+func (p *upstreamProxy) connect(conn net.Conn, addr string) (net.Conn, error) {
+    req := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n"
+    if len(p.auth) > 0 {
+        req += "Proxy-Authorization: " + p.auth + "\r\n"
+    }
+    req += "\r\n"
+    if _, err := conn.Write([]byte(req)); err != nil {
+        return nil, err
+    }
+
+    br := bufio.NewReader(conn)
+    resp := fasthttp.AcquireResponse()
+    defer fasthttp.ReleaseResponse(resp)
+    resp.SkipBody = true
+    if err := resp.Read(br); err != nil {
+        return nil, err
+    }
+    if resp.StatusCode() != fasthttp.StatusOK {
+        return nil, fmt.Errorf("CONNECT to %s failed with status %d", addr, resp.StatusCode())
+    }
+    if br.Buffered() > 0 {
+        // The target already sent data through the tunnel, keep it around
+        return &bufferedConn{Conn: conn, r: br}, nil
+    }
+    return conn, nil
+}
+
+// socks5 performs the SOCKS5 handshake (RFC 1928) with optional username / password authentication (RFC 1929)
+func (p *upstreamProxy) socks5(conn net.Conn, addr string) error {
+    host, portStr, err := net.SplitHostPort(addr)
+    if err != nil {
+        return err
+    }
+    port, err := strconv.Atoi(portStr)
+    if err != nil {
+        return fmt.Errorf("invalid port: %s", portStr)
+    }
+
+    methods := []byte{socks5AuthNone}
+    if p.url.User != nil {
+        methods = append(methods, socks5AuthPassword)
+    }
+    greeting := append([]byte{socks5Version, byte(len(methods))}, methods...)
+    if _, err := conn.Write(greeting); err != nil {
+        return err
+    }
+    reply := make([]byte, 2)
+    if _, err := io.ReadFull(conn, reply); err != nil {
+        return err
+    }
+    if reply[0] != socks5Version {
+        return fmt.Errorf("unexpected SOCKS version %d", reply[0])
+    }
+    switch reply[1] {
+    case socks5AuthNone:
+    case socks5AuthPassword:
+        if p.url.User == nil {
+            return fmt.Errorf("SOCKS5 server requires authentication")
+        }
+        user := p.url.User.Username()
+        pass, _ := p.url.User.Password()
+        if len(user) > 255 || len(pass) > 255 {
+            return fmt.Errorf("SOCKS5 credentials too long")
+        }
+        authreq := []byte{0x01, byte(len(user))}
+        authreq = append(authreq, user...)
+        authreq = append(authreq, byte(len(pass)))
+        authreq = append(authreq, pass...)
+        if _, err := conn.Write(authreq); err != nil {
+            return err
+        }
+        if _, err := io.ReadFull(conn, reply); err != nil {
+            return err
+        }
+        if reply[1] != 0x00 {
+            return fmt.Errorf("SOCKS5 authentication failed")
+        }
+    case socks5AuthNoMethods:
+        return fmt.Errorf("no acceptable SOCKS5 authentication methods")
+    default:
+        return fmt.Errorf("unsupported SOCKS5 authentication method %d", reply[1])
+    }
+
+    req := []byte{socks5Version, socks5CmdConnect, 0x00}
+    ip := net.ParseIP(host)
+    if ip == nil && p.url.Scheme == "socks5" {
+        // socks5 resolves names locally, socks5h leaves it to the proxy
+        ipaddr, err := net.ResolveIPAddr("ip", host)
+        if err != nil {
+            return err
+        }
+        ip = ipaddr.IP
+    }
+    if ip4 := ip.To4(); ip4 != nil {
+        req = append(req, socks5AtypIPv4)
+        req = append(req, ip4...)
+    } else if ip != nil {
+        req = append(req, socks5AtypIPv6)
+        req = append(req, ip.To16()...)
+    } else {
+        if len(host) > 255 {
+            return fmt.Errorf("hostname too long: %s", host)
+        }
+        req = append(req, socks5AtypDomain, byte(len(host)))
+        req = append(req, host...)
+    }
+    portBytes := make([]byte, 2)
+    binary.BigEndian.PutUint16(portBytes, uint16(port))
+    req = append(req, portBytes...)
+    if _, err := conn.Write(req); err != nil {
+        return err
+    }
+
+    // Reply: VER REP RSV ATYP BND.ADDR BND.PORT
+    head := make([]byte, 4)
+    if _, err := io.ReadFull(conn, head); err != nil {
+        return err
+    }
+    if head[1] != 0x00 {
+        return fmt.Errorf("SOCKS5 connect to %s failed with code %d", addr, head[1])
+    }
+    var skip int
+    switch head[3] {
+    case socks5AtypIPv4:
+        skip = net.IPv4len
+    case socks5AtypIPv6:
+        skip = net.IPv6len
+    case socks5AtypDomain:
+        l := make([]byte, 1)
+        if _, err := io.ReadFull(conn, l); err != nil {
+            return err
+        }
+        skip = int(l[0])
+    default:
+        return fmt.Errorf("unexpected SOCKS5 address type %d", head[3])
+    }
+    _, err = io.ReadFull(conn, make([]byte, skip+2))
+    return err
+}
+
+// bufferedConn is a net.Conn that drains an already filled bufio.Reader before reading from the connection
+type bufferedConn struct {
+    net.Conn
+    r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+    return c.r.Read(b)
+}
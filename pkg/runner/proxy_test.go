@@ -0,0 +1,351 @@
+package runner
+
+import (
+    "bufio"
+    "context"
+    "encoding/binary"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+// proxyHandshake records what the test proxy got from the client
+type proxyHandshake struct {
+    target string
+    auth   string
+    atyp   byte
+}
+
+// startTestProxy serves the handshake of every connection, and echoes the tunnelled data back if
+// the handshake succeeded
+func startTestProxy(t *testing.T, handshake func(conn net.Conn, br *bufio.Reader) (proxyHandshake, bool)) (net.Listener, chan proxyHandshake) {
+    l, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("Could not listen: %s", err)
+    }
+    seen := make(chan proxyHandshake, 10)
+    go func() {
+        for {
+            conn, err := l.Accept()
+            if err != nil {
+                return
+            }
+            go func() {
+                defer conn.Close()
+                br := bufio.NewReader(conn)
+                h, ok := handshake(conn, br)
+                seen <- h
+                if ok {
+                    io.Copy(conn, br)
+                }
+            }()
+        }
+    }()
+    return l, seen
+}
+
+// connectProxy accepts the CONNECT requests having the Proxy-Authorization auth, if given
+func connectProxy(auth string) func(conn net.Conn, br *bufio.Reader) (proxyHandshake, bool) {
+    return func(conn net.Conn, br *bufio.Reader) (proxyHandshake, bool) {
+        req, err := http.ReadRequest(br)
+        if err != nil {
+            return proxyHandshake{}, false
+        }
+        h := proxyHandshake{target: req.Host, auth: req.Header.Get("Proxy-Authorization")}
+        if req.Method != "CONNECT" {
+            conn.Write([]byte("HTTP/1.1 405 Method Not Allowed\r\nContent-Length: 0\r\n\r\n"))
+            return h, false
+        }
+        if auth != "" && h.auth != auth {
+            conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\nContent-Length: 0\r\n\r\n"))
+            return h, false
+        }
+        conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+        return h, true
+    }
+}
+
+// socks5Proxy accepts the SOCKS5 connect requests, requiring the user and pass if given
+func socks5Proxy(user, pass string) func(conn net.Conn, br *bufio.Reader) (proxyHandshake, bool) {
+    return func(conn net.Conn, br *bufio.Reader) (proxyHandshake, bool) {
+        var h proxyHandshake
+        head := make([]byte, 2)
+        if _, err := io.ReadFull(br, head); err != nil {
+            return h, false
+        }
+        methods := make([]byte, head[1])
+        io.ReadFull(br, methods)
+        method := byte(socks5AuthNone)
+        if user != "" {
+            method = socks5AuthPassword
+        }
+        if !strings.Contains(string(methods), string([]byte{method})) {
+            conn.Write([]byte{socks5Version, socks5AuthNoMethods})
+            return h, false
+        }
+        conn.Write([]byte{socks5Version, method})
+        if method == socks5AuthPassword {
+            l := make([]byte, 2)
+            io.ReadFull(br, l)
+            u := make([]byte, l[1])
+            io.ReadFull(br, u)
+            io.ReadFull(br, l[:1])
+            p := make([]byte, l[0])
+            io.ReadFull(br, p)
+            h.auth = string(u) + ":" + string(p)
+            if string(u) != user || string(p) != pass {
+                conn.Write([]byte{0x01, 0x01})
+                return h, false
+            }
+            conn.Write([]byte{0x01, 0x00})
+        }
+        req := make([]byte, 4)
+        if _, err := io.ReadFull(br, req); err != nil {
+            return h, false
+        }
+        h.atyp = req[3]
+        var host string
+        switch h.atyp {
+        case socks5AtypIPv4:
+            ip := make([]byte, net.IPv4len)
+            io.ReadFull(br, ip)
+            host = net.IP(ip).String()
+        case socks5AtypIPv6:
+            ip := make([]byte, net.IPv6len)
+            io.ReadFull(br, ip)
+            host = net.IP(ip).String()
+        case socks5AtypDomain:
+            l := make([]byte, 1)
+            io.ReadFull(br, l)
+            name := make([]byte, l[0])
+            io.ReadFull(br, name)
+            host = string(name)
+        }
+        port := make([]byte, 2)
+        io.ReadFull(br, port)
+        h.target = net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+        conn.Write([]byte{socks5Version, 0x00, 0x00, socks5AtypIPv4, 127, 0, 0, 1, 0, 0})
+        return h, true
+    }
+}
+
+// echoThrough checks that the data written to the tunnel comes back
+func echoThrough(conn net.Conn) bool {
+    defer conn.Close()
+    conn.SetDeadline(time.Now().Add(2 * time.Second))
+    if _, err := conn.Write([]byte("ping")); err != nil {
+        return false
+    }
+    buf := make([]byte, 4)
+    _, err := io.ReadFull(conn, buf)
+    return err == nil && string(buf) == "ping"
+}
+
+// startForwardProxy starts an HTTP proxy tunnelling the CONNECT requests to their target, and
+// answering the other requests with the handler. The targets of the tunnels are sent to the channel.
+func startForwardProxy(handler http.HandlerFunc) (*httptest.Server, chan string) {
+    tunnels := make(chan string, 10)
+    proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != "CONNECT" {
+            handler(w, r)
+            return
+        }
+        tunnels <- r.Host
+        upstream, err := net.Dial("tcp", r.Host)
+        if err != nil {
+            w.WriteHeader(http.StatusBadGateway)
+            return
+        }
+        conn, brw, _ := w.(http.Hijacker).Hijack()
+        conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+        go func() {
+            io.Copy(upstream, brw)
+            upstream.Close()
+        }()
+        io.Copy(conn, upstream)
+        conn.Close()
+    }))
+    return proxy, tunnels
+}
+
+func TestUpstreamProxyConnect(t *testing.T) {
+    for _, test := range []struct {
+        name     string
+        auth     string
+        userinfo string
+        wantErr  bool
+        wantAuth string
+    }{
+        {name: "no auth"},
+        {name: "auth", auth: "Basic dXNlcjpwYXNz", userinfo: "user:pass@", wantAuth: "Basic dXNlcjpwYXNz"},
+        {name: "wrong auth", auth: "Basic dXNlcjpwYXNz", userinfo: "user:nope@", wantErr: true, wantAuth: "Basic dXNlcjpub3Bl"},
+        {name: "no credentials", auth: "Basic dXNlcjpwYXNz", wantErr: true},
+    } {
+        l, seen := startTestProxy(t, connectProxy(test.auth))
+        defer l.Close()
+        proxy, err := newUpstreamProxy("http://"+test.userinfo+l.Addr().String(), 2*time.Second)
+        if err != nil {
+            t.Fatalf("%s: %s", test.name, err)
+        }
+        conn, err := proxy.Dial("target.example:443")
+        h := <-seen
+        if h.target != "target.example:443" || h.auth != test.wantAuth {
+            t.Errorf("%s: proxy got target %q and auth %q", test.name, h.target, h.auth)
+        }
+        if test.wantErr {
+            if err == nil || !strings.Contains(err.Error(), "407") {
+                t.Errorf("%s: expected the 407 status in the error, got %v", test.name, err)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.name, err)
+            continue
+        }
+        if !echoThrough(conn) {
+            t.Errorf("%s: the tunnel did not pass the data through", test.name)
+        }
+    }
+}
+
+func TestUpstreamProxySocks5(t *testing.T) {
+    for _, test := range []struct {
+        name       string
+        scheme     string
+        user, pass string
+        userinfo   string
+        target     string
+        wantErr    bool
+        wantAtyp   byte
+        wantTarget string
+    }{
+        {name: "socks5h keeps the name", scheme: "socks5h", target: "localhost:80", wantAtyp: socks5AtypDomain, wantTarget: "localhost:80"},
+        {name: "socks5 resolves the name", scheme: "socks5", target: "localhost:80", wantAtyp: 0},
+        {name: "ip address", scheme: "socks5h", target: "127.0.0.2:80", wantAtyp: socks5AtypIPv4, wantTarget: "127.0.0.2:80"},
+        {name: "auth", scheme: "socks5", user: "user", pass: "pass", userinfo: "user:pass@", target: "127.0.0.1:80", wantAtyp: socks5AtypIPv4, wantTarget: "127.0.0.1:80"},
+        {name: "wrong auth", scheme: "socks5", user: "user", pass: "pass", userinfo: "user:nope@", target: "127.0.0.1:80", wantErr: true},
+        {name: "no credentials", scheme: "socks5", user: "user", pass: "pass", target: "127.0.0.1:80", wantErr: true},
+    } {
+        l, seen := startTestProxy(t, socks5Proxy(test.user, test.pass))
+        defer l.Close()
+        proxy, err := newUpstreamProxy(test.scheme+"://"+test.userinfo+l.Addr().String(), 2*time.Second)
+        if err != nil {
+            t.Fatalf("%s: %s", test.name, err)
+        }
+        conn, err := proxy.Dial(test.target)
+        h := <-seen
+        if test.wantErr {
+            if err == nil {
+                t.Errorf("%s: expected an error", test.name)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.name, err)
+            continue
+        }
+        if test.wantAtyp == 0 {
+            // Resolved locally, to either of the loopback addresses
+            if h.atyp == socks5AtypDomain {
+                t.Errorf("%s: the name was sent to the proxy", test.name)
+            }
+        } else if h.atyp != test.wantAtyp || h.target != test.wantTarget {
+            t.Errorf("%s: expected address type %d and target %s, got %d and %s", test.name, test.wantAtyp, test.wantTarget, h.atyp, h.target)
+        }
+        if !echoThrough(conn) {
+            t.Errorf("%s: the tunnel did not pass the data through", test.name)
+        }
+    }
+}
+
+func TestUpstreamProxyDialContext(t *testing.T) {
+    // A proxy accepting the connection but never answering the handshake
+    l, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("Could not listen: %s", err)
+    }
+    defer l.Close()
+    go func() {
+        for {
+            conn, err := l.Accept()
+            if err != nil {
+                return
+            }
+            defer conn.Close()
+        }
+    }()
+    proxy, _ := newUpstreamProxy("http://"+l.Addr().String(), 10*time.Second)
+    ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+    defer cancel()
+    start := time.Now()
+    _, err = proxy.DialContext(ctx, "target.example:443")
+    if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+        t.Errorf("Expected the deadline of the context in the error, got %v", err)
+    }
+    if time.Since(start) > 2*time.Second {
+        t.Errorf("The handshake was not cut by the context")
+    }
+}
+
+func TestSimpleRunnerProxyAbsoluteForm(t *testing.T) {
+    seen := make(chan proxyHandshake, 1)
+    proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        seen <- proxyHandshake{target: r.RequestURI, auth: r.Header.Get("Proxy-Authorization")}
+        w.Write([]byte("proxied"))
+    }))
+    defer proxy.Close()
+
+    conf := ffuf.NewConfig(context.Background())
+    conf.ProxyURL = "http://user:pass@" + proxy.Listener.Addr().String()
+    runner := NewSimpleRunner(&conf, false)
+    resp, err := runner.Execute(&ffuf.Request{Method: "GET", Url: "http://target.example/path?q=1"})
+    if err != nil {
+        t.Fatalf("Unexpected error %s", err)
+    }
+    h := <-seen
+    if h.target != "http://target.example/path?q=1" {
+        t.Errorf("Expected the request line in absolute-form, got %q", h.target)
+    }
+    if h.auth != "Basic dXNlcjpwYXNz" {
+        t.Errorf("Expected the proxy credentials, got %q", h.auth)
+    }
+    if string(resp.Data) != "proxied" {
+        t.Errorf("Expected the response of the proxy, got %q", resp.Data)
+    }
+}
+
+func TestSimpleRunnerProxyRedirectToHTTPS(t *testing.T) {
+    // The plain HTTP target redirects to https, which is reached through a CONNECT tunnel
+    targetAuth := make(chan string, 1)
+    target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        targetAuth <- r.Header.Get("Proxy-Authorization")
+        w.Write([]byte("secure"))
+    }))
+    defer target.Close()
+    proxy, _ := startForwardProxy(func(w http.ResponseWriter, r *http.Request) {
+        http.Redirect(w, r, target.URL+"/next", http.StatusFound)
+    })
+    defer proxy.Close()
+
+    conf := ffuf.NewConfig(context.Background())
+    conf.ProxyURL = "http://user:pass@" + proxy.Listener.Addr().String()
+    conf.FollowRedirects = true
+    runner := NewSimpleRunner(&conf, false)
+    resp, err := runner.Execute(&ffuf.Request{Method: "GET", Url: "http://target.example/"})
+    if err != nil {
+        t.Fatalf("Unexpected error %s", err)
+    }
+    if string(resp.Data) != "secure" {
+        t.Fatalf("Expected the response of the https target, got %q", resp.Data)
+    }
+    if auth := <-targetAuth; auth != "" {
+        t.Errorf("The proxy credentials were sent to the target: %q", auth)
+    }
+}
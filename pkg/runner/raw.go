@@ -0,0 +1,289 @@
+package runner
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/tls"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net"
+    "net/textproto"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+    "github.com/valyala/fasthttp"
+)
+
+// RawRunner writes the raw request template (-request) to a TCP / TLS socket byte-for-byte,
+// only replacing the keywords. Redirects are never followed.
+type RawRunner struct {
+    config *ffuf.Config
+    dial   func(addr string) (net.Conn, error)
+    replay bool
+    marker []string
+}
+
+func NewRawRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
+    var rawrunner RawRunner
+    rawrunner.config = conf
+    rawrunner.replay = replay
+    rawrunner.marker = replayMarker(conf, replay)
+    rawrunner.dial = func(addr string) (net.Conn, error) {
+        return fasthttp.DialDualStackTimeout(addr, 30*time.Second)
+    }
+    proxyURL := runnerProxyURL(conf, replay)
+    if len(proxyURL) > 0 {
+        proxy, err := newUpstreamProxy(proxyURL, 30*time.Second)
+        if err != nil {
+            // Never fall back to a direct connection when a proxy was requested
+            rawrunner.dial = func(addr string) (net.Conn, error) {
+                return nil, fmt.Errorf("invalid proxy url %s: %s", proxyURL, err)
+            }
+        } else {
+            // The request bytes are not touched, so every target gets tunnelled
+            rawrunner.dial = proxy.Dial
+        }
+    }
+    return &rawrunner
+}
+
+func (r *RawRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
+    req := prepareRequest(r.config, input)
+    raw := []byte(r.config.RawRequest)
+    for keyword, inputitem := range input {
+        raw = bytes.Replace(raw, []byte(keyword), inputitem, -1)
+    }
+    req.RawRequest = raw
+    return req, nil
+}
+
+func (r *RawRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+    target, err := url.Parse(req.Url)
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    isTLS := target.Scheme == "https"
+    addr := target.Host
+    if len(target.Port()) == 0 {
+        if isTLS {
+            addr = net.JoinHostPort(target.Hostname(), "443")
+        } else {
+            addr = net.JoinHostPort(target.Hostname(), "80")
+        }
+    }
+    req.Host = target.Host
+
+    start := time.Now()
+    rawConn, err := r.dial(addr)
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    defer rawConn.Close()
+    timedConn := newTimingConn(rawConn)
+    timing := timedConn.startExchange()
+    var conn net.Conn = timedConn
+    if isTLS {
+        tlsConn := tls.Client(conn, &tls.Config{
+            ServerName:         target.Hostname(),
+            InsecureSkipVerify: true,
+            Renegotiation:      tls.RenegotiateOnceAsClient,
+        })
+        conn = tlsConn
+    }
+    conn.SetDeadline(time.Now().Add(time.Duration(r.config.Timeout) * time.Second))
+
+    rawReq := req.RawRequest
+    if r.replay && len(r.marker) == 2 {
+        rawReq = insertRawHeader(rawReq, r.marker[0]+": "+r.marker[1])
+    }
+    if _, err := conn.Write(rawReq); err != nil {
+        return ffuf.Response{}, err
+    }
+
+    // Keep a copy of everything read for -od
+    received := &bytes.Buffer{}
+    br := bufio.NewReader(io.TeeReader(conn, &limitedWriter{w: received, n: MAX_DOWNLOAD_SIZE}))
+    resp, err := readRawResponse(br, req, r.config.IgnoreBody || r.replay)
+    if err != nil {
+        return ffuf.Response{}, err
+    }
+    resp.TimeToFirstByte = timeToFirstByte(start, timing.FirstByte())
+    resp.Duration = time.Since(start)
+    if r.replay || resp.Cancelled {
+        return resp, nil
+    }
+
+    respBody, err := decodeBody(headerValue(resp.Headers, "Content-Encoding"), resp.Data)
+    if err != nil {
+        // Leave the body as it was received
+        respBody = resp.Data
+    }
+    setResponseBody(&resp, respBody)
+
+    statusFormat := fmt.Sprintf("Status: %d, Size: %d, Words: %d, Lines: %d", resp.StatusCode, resp.ContentLength, resp.ContentWords, resp.ContentLines)
+    if len(r.config.OutputDirectory) > 0 {
+        resp.Request.Raw = fmt.Sprintf("%s\n\n%s\n\n%s", req.Url, statusFormat, rawReq)
+        resp.Raw = received.String()
+    }
+    return resp, nil
+}
+
+// readRawResponse parses the response leniently: a malformed status line results in status code 0,
+// malformed header lines are skipped and a broken body is returned as far as it could be read.
+func readRawResponse(br *bufio.Reader, req *ffuf.Request, skipBody bool) (ffuf.Response, error) {
+    resp := ffuf.Response{
+        Request: req,
+        Headers: map[string][]string{},
+    }
+
+    statusLine, err := br.ReadString('\n')
+    if len(statusLine) == 0 && err != nil {
+        return resp, err
+    }
+    if !strings.HasPrefix(statusLine, "HTTP/") {
+        // Not a HTTP response at all (HTTP/0.9 style), everything is body
+        rest, _ := ioutil.ReadAll(io.LimitReader(br, MAX_DOWNLOAD_SIZE))
+        resp.Data = append([]byte(statusLine), rest...)
+        return resp, nil
+    }
+    resp.StatusCode = parseRawStatus(statusLine)
+
+    for {
+        line, err := br.ReadString('\n')
+        trimmed := strings.TrimRight(line, "\r\n")
+        if len(trimmed) == 0 {
+            break
+        }
+        if p := strings.SplitN(trimmed, ":", 2); len(p) == 2 {
+            key := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(p[0]))
+            resp.Headers[key] = append(resp.Headers[key], strings.TrimSpace(p[1]))
+        }
+        if err != nil {
+            // Connection closed while reading the headers
+            return resp, nil
+        }
+    }
+
+    if skipBody || req.Method == "HEAD" || resp.StatusCode == 204 || resp.StatusCode == 304 || (resp.StatusCode >= 100 && resp.StatusCode < 200) {
+        if size, err := strconv.ParseInt(headerValue(resp.Headers, "Content-Length"), 10, 64); err == nil {
+            resp.ContentLength = size
+        }
+        resp.Cancelled = skipBody
+        return resp, nil
+    }
+
+    var body []byte
+    if strings.EqualFold(headerValue(resp.Headers, "Transfer-Encoding"), "chunked") {
+        body = readRawChunked(br)
+    } else if size, err := strconv.ParseInt(headerValue(resp.Headers, "Content-Length"), 10, 64); err == nil && size >= 0 {
+        if size > MAX_DOWNLOAD_SIZE {
+            resp.ContentLength = size
+            resp.Cancelled = true
+            return resp, nil
+        }
+        body = make([]byte, size)
+        n, _ := io.ReadFull(br, body)
+        body = body[:n]
+    } else {
+        // No framing, read until the server closes the connection or the timeout hits
+        body, _ = ioutil.ReadAll(io.LimitReader(br, MAX_DOWNLOAD_SIZE))
+    }
+    resp.Data = body
+    return resp, nil
+}
+
+// parseRawStatus returns the status code from a status line, or 0 if it cannot be parsed
+func parseRawStatus(statusLine string) int64 {
+    fields := strings.Fields(statusLine)
+    if len(fields) < 2 {
+        return 0
+    }
+    code := fields[1]
+    if len(code) > 3 {
+        code = code[:3]
+    }
+    status, err := strconv.ParseInt(code, 10, 64)
+    if err != nil {
+        return 0
+    }
+    return status
+}
+
+// readRawChunked decodes a chunked body, returning whatever was decoded before an error
+func readRawChunked(br *bufio.Reader) []byte {
+    body := make([]byte, 0)
+    for len(body) <= MAX_DOWNLOAD_SIZE {
+        line, err := br.ReadString('\n')
+        sizeStr := strings.TrimSpace(strings.SplitN(line, ";", 2)[0])
+        size, perr := strconv.ParseInt(sizeStr, 16, 64)
+        if perr != nil || size < 0 {
+            return body
+        }
+        if size == 0 {
+            // Last chunk, read the trailers up to the terminating empty line
+            for err == nil {
+                line, err = br.ReadString('\n')
+                if len(strings.TrimRight(line, "\r\n")) == 0 {
+                    break
+                }
+            }
+            return body
+        }
+        chunk := make([]byte, size)
+        n, rerr := io.ReadFull(br, chunk)
+        body = append(body, chunk[:n]...)
+        if err != nil || rerr != nil {
+            return body
+        }
+        // Chunk terminating CRLF
+        br.ReadString('\n')
+    }
+    return body
+}
+
+// insertRawHeader adds a header line right after the request line
+func insertRawHeader(raw []byte, header string) []byte {
+    idx := bytes.IndexByte(raw, '\n')
+    if idx == -1 {
+        return raw
+    }
+    eol := "\n"
+    if idx > 0 && raw[idx-1] == '\r' {
+        eol = "\r\n"
+    }
+    out := make([]byte, 0, len(raw)+len(header)+len(eol))
+    out = append(out, raw[:idx+1]...)
+    out = append(out, header+eol...)
+    out = append(out, raw[idx+1:]...)
+    return out
+}
+
+// headerValue returns the first value of a response header
+func headerValue(headers map[string][]string, name string) string {
+    if v, ok := headers[name]; ok && len(v) > 0 {
+        return v[0]
+    }
+    return ""
+}
+
+// limitedWriter writes up to n bytes to w, silently discarding the rest
+type limitedWriter struct {
+    w io.Writer
+    n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+    if l.n > 0 {
+        chunk := p
+        if len(chunk) > l.n {
+            chunk = chunk[:l.n]
+        }
+        l.w.Write(chunk)
+        l.n -= len(chunk)
+    }
+    return len(p), nil
+}
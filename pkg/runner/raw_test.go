@@ -0,0 +1,162 @@
+package runner
+
+import (
+    "bufio"
+    "io/ioutil"
+    "strings"
+    "testing"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+)
+
+func TestReadRawResponse(t *testing.T) {
+    for _, test := range []struct {
+        name       string
+        method     string
+        raw        string
+        skipBody   bool
+        wantStatus int64
+        wantBody   string
+        wantLength int64
+        wantRest   string
+    }{
+        {
+            name:       "content-length",
+            raw:        "HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhelloEXTRA",
+            wantStatus: 200,
+            wantBody:   "hello",
+            wantRest:   "EXTRA",
+        },
+        {
+            name:       "content-length with a short body",
+            raw:        "HTTP/1.1 200 OK\r\nContent-Length: 50\r\n\r\nshort",
+            wantStatus: 200,
+            wantBody:   "short",
+        },
+        {
+            name:       "chunked",
+            raw:        "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n6;ext=1\r\n world\r\n0\r\n\r\nEXTRA",
+            wantStatus: 200,
+            wantBody:   "hello world",
+            wantRest:   "EXTRA",
+        },
+        {
+            name:       "chunked with trailers",
+            raw:        "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\nX-Checksum: abc\r\nX-Other: def\r\n\r\nEXTRA",
+            wantStatus: 200,
+            wantBody:   "hello",
+            wantRest:   "EXTRA",
+        },
+        {
+            name:       "bad chunk size",
+            raw:        "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\nzz\r\nmore\r\n0\r\n\r\n",
+            wantStatus: 200,
+            wantBody:   "hello",
+            wantRest:   "more\r\n0\r\n\r\n",
+        },
+        {
+            name:       "negative chunk size",
+            raw:        "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n-5\r\nhello\r\n",
+            wantStatus: 200,
+            wantRest:   "hello\r\n",
+        },
+        {
+            name:       "chunk cut short",
+            raw:        "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\na\r\nhello",
+            wantStatus: 200,
+            wantBody:   "hello",
+        },
+        {
+            name:       "no framing",
+            raw:        "HTTP/1.0 200 OK\r\nServer: test\r\n\r\nuntil the end",
+            wantStatus: 200,
+            wantBody:   "until the end",
+        },
+        {
+            name:       "head",
+            method:     "HEAD",
+            raw:        "HTTP/1.1 200 OK\r\nContent-Length: 1234\r\n\r\nEXTRA",
+            wantStatus: 200,
+            wantLength: 1234,
+            wantRest:   "EXTRA",
+        },
+        {
+            name:       "204",
+            raw:        "HTTP/1.1 204 No Content\r\n\r\nEXTRA",
+            wantStatus: 204,
+            wantRest:   "EXTRA",
+        },
+        {
+            name:       "304",
+            raw:        "HTTP/1.1 304 Not Modified\r\nContent-Length: 42\r\n\r\nEXTRA",
+            wantStatus: 304,
+            wantLength: 42,
+            wantRest:   "EXTRA",
+        },
+        {
+            name:       "skip body",
+            raw:        "HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhello",
+            skipBody:   true,
+            wantStatus: 200,
+            wantLength: 5,
+            wantRest:   "hello",
+        },
+        {
+            name:       "malformed status line",
+            raw:        "HTTP/1.1 abc\r\nContent-Length: 2\r\n\r\nhi",
+            wantStatus: 0,
+            wantBody:   "hi",
+        },
+        {
+            name:     "not http",
+            raw:      "hello\nworld",
+            wantBody: "hello\nworld",
+        },
+    } {
+        method := test.method
+        if method == "" {
+            method = "GET"
+        }
+        br := bufio.NewReader(strings.NewReader(test.raw))
+        resp, err := readRawResponse(br, &ffuf.Request{Method: method}, test.skipBody)
+        if err != nil {
+            t.Errorf("%s: unexpected error %s", test.name, err)
+            continue
+        }
+        if resp.StatusCode != test.wantStatus {
+            t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, resp.StatusCode)
+        }
+        if string(resp.Data) != test.wantBody {
+            t.Errorf("%s: expected body %q, got %q", test.name, test.wantBody, resp.Data)
+        }
+        if resp.ContentLength != test.wantLength {
+            t.Errorf("%s: expected content length %d, got %d", test.name, test.wantLength, resp.ContentLength)
+        }
+        if rest, _ := ioutil.ReadAll(br); string(rest) != test.wantRest {
+            t.Errorf("%s: expected %q to be left unread, got %q", test.name, test.wantRest, rest)
+        }
+    }
+}
+
+func TestReadRawResponseEmpty(t *testing.T) {
+    if _, err := readRawResponse(bufio.NewReader(strings.NewReader("")), &ffuf.Request{Method: "GET"}, false); err == nil {
+        t.Errorf("Expected an error for an empty response")
+    }
+}
+
+func TestReadRawResponseHeaders(t *testing.T) {
+    raw := "HTTP/1.1 302 Found\r\nlocation: /next\r\nSet-Cookie: a=1\r\nset-cookie: b=2\r\nnot a header\r\nContent-Length: 0\r\n\r\n"
+    resp, err := readRawResponse(bufio.NewReader(strings.NewReader(raw)), &ffuf.Request{Method: "GET"}, false)
+    if err != nil {
+        t.Fatalf("Unexpected error %s", err)
+    }
+    if resp.GetRedirectLocation(false) != "/next" {
+        t.Errorf("Expected the location /next, got %q", resp.GetRedirectLocation(false))
+    }
+    if cookies := resp.Headers["Set-Cookie"]; len(cookies) != 2 || cookies[0] != "a=1" || cookies[1] != "b=2" {
+        t.Errorf("Expected both of the cookies, got %v", cookies)
+    }
+    if len(resp.Headers) != 3 {
+        t.Errorf("Expected the malformed header line to be skipped, got %v", resp.Headers)
+    }
+}
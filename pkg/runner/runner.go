@@ -1,10 +1,94 @@
 package runner
 
 import (
+    "bytes"
+    "compress/gzip"
+    "compress/zlib"
+    "io"
+    "io/ioutil"
+    "net/textproto"
+    "strings"
+    "unicode/utf8"
+
     "github.com/theblackturtle/ffuf/pkg/ffuf"
 )
 
 func NewRunnerByName(name string, conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
-    // We have only one Runner at the moment
+    if name == "http2" {
+        return NewHTTP2Runner(conf, replay)
+    }
+    if name == "raw" {
+        return NewRawRunner(conf, replay)
+    }
+    // Default to the fasthttp based HTTP/1.1 runner
     return NewSimpleRunner(conf, replay)
 }
+
+// prepareRequest builds a Request from the configuration, replacing the keywords with input values
+func prepareRequest(conf *ffuf.Config, input map[string][]byte) ffuf.Request {
+    req := ffuf.NewRequest(conf)
+
+    req.Headers = conf.Headers
+    req.Url = conf.Url
+    req.Method = conf.Method
+    req.Data = []byte(conf.Data)
+
+    for keyword, inputitem := range input {
+        req.Method = strings.Replace(req.Method, keyword, string(inputitem), -1)
+        headers := make(map[string]string, 0)
+        for h, v := range req.Headers {
+            var CanonicalHeader = textproto.CanonicalMIMEHeaderKey(strings.Replace(h, keyword, string(inputitem), -1))
+            headers[CanonicalHeader] = strings.Replace(v, keyword, string(inputitem), -1)
+        }
+        req.Headers = headers
+        req.Url = strings.Replace(req.Url, keyword, string(inputitem), -1)
+        req.Data = []byte(strings.Replace(string(req.Data), keyword, string(inputitem), -1))
+    }
+
+    req.Input = input
+    return req
+}
+
+// setResponseBody stores the response body and the size counters used by filters
+func setResponseBody(resp *ffuf.Response, body []byte) {
+    resp.ContentLength = int64(utf8.RuneCountInString(string(body)))
+    resp.Data = body
+
+    resp.ContentWords = int64(len(strings.Split(string(resp.Data), " ")))
+    resp.ContentLines = int64(len(strings.Split(string(resp.Data), "\n")))
+}
+
+// runnerProxyURL returns the upstream proxy url to use. Replay runner only uses the replay proxy.
+func runnerProxyURL(conf *ffuf.Config, replay bool) string {
+    if replay {
+        return conf.ReplayProxyURL
+    }
+    return conf.ProxyURL
+}
+
+// replayMarker returns the name and value of the header marking the replayed requests, if any
+func replayMarker(conf *ffuf.Config, replay bool) []string {
+    if !replay || len(conf.ReplayMarker) == 0 {
+        return nil
+    }
+    return strings.SplitN(conf.ReplayMarker, ": ", 2)
+}
+
+// decodeBody decompresses gzip and deflate encoded response bodies
+func decodeBody(encoding string, body []byte) ([]byte, error) {
+    var reader io.ReadCloser
+    var err error
+    switch encoding {
+    case "gzip":
+        reader, err = gzip.NewReader(bytes.NewReader(body))
+    case "deflate":
+        reader, err = zlib.NewReader(bytes.NewReader(body))
+    default:
+        return body, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    defer reader.Close()
+    return ioutil.ReadAll(reader)
+}
@@ -6,11 +6,8 @@ import (
     "errors"
     "fmt"
     "net"
-    "net/textproto"
     "strconv"
-    "strings"
     "time"
-    "unicode/utf8"
 
     "github.com/theblackturtle/ffuf/pkg/ffuf"
     "github.com/valyala/fasthttp"
@@ -20,23 +17,58 @@ import (
 const (
     MAX_DOWNLOAD_SIZE = 5242880
     MaxRedirectTimes  = 16
+    DefaultUserAgent  = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_3) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.132 Safari/537.36"
 )
 
 type SimpleRunner struct {
-    config *ffuf.Config
-    client *fasthttp.Client
+    config      *ffuf.Config
+    client      *fasthttp.Client
+    proxy       *upstreamProxy
+    proxyClient *fasthttp.HostClient
+    replay      bool
+    marker      []string
 }
 
 func NewSimpleRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
     var simplerunner SimpleRunner
     simplerunner.config = conf
+    simplerunner.replay = replay
+    simplerunner.marker = replayMarker(conf, replay)
+    proxyURL := runnerProxyURL(conf, replay)
+    dial := timedDial(func(addr string) (net.Conn, error) {
+        return fasthttp.DialDualStackTimeout(addr, 30*time.Second)
+    })
+    if len(proxyURL) > 0 {
+        proxy, err := newUpstreamProxy(proxyURL, 30*time.Second)
+        if err != nil {
+            // Never fall back to a direct connection when a proxy was requested
+            dial = func(addr string) (net.Conn, error) {
+                return nil, fmt.Errorf("invalid proxy url %s: %s", proxyURL, err)
+            }
+        } else {
+            simplerunner.proxy = proxy
+            // HTTPS targets and SOCKS5 proxies are tunnelled, plain HTTP targets
+            // are sent to HTTP proxies directly in absolute-form
+            dial = timedDial(proxy.Dial)
+            if proxy.isHTTP() {
+                simplerunner.proxyClient = &fasthttp.HostClient{
+                    Addr:                     proxy.addr,
+                    NoDefaultUserAgentHeader: true,
+                    Dial: timedDial(func(addr string) (net.Conn, error) {
+                        return proxy.dialProxy()
+                    }),
+                    ReadBufferSize:      48 << 10,
+                    WriteBufferSize:     48 << 10,
+                    MaxResponseBodySize: MAX_DOWNLOAD_SIZE,
+                }
+            }
+        }
+    }
     simplerunner.client = &fasthttp.Client{
         NoDefaultUserAgentHeader: true,
-        Dial: func(addr string) (net.Conn, error) {
-            return fasthttp.DialDualStackTimeout(addr, 30*time.Second)
-        },
-        ReadBufferSize:  48 << 10,
-        WriteBufferSize: 48 << 10,
+        Dial:                     dial,
+        ReadBufferSize:           48 << 10,
+        WriteBufferSize:          48 << 10,
         TLSConfig: &tls.Config{
             InsecureSkipVerify: true,
             Renegotiation:      tls.RenegotiateOnceAsClient, // For "local error: tls: no renegotiation"
@@ -48,35 +80,12 @@ func NewSimpleRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
 }
 
 func (r *SimpleRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
-    req := ffuf.NewRequest(r.config)
-
-    req.Headers = r.config.Headers
-    req.Url = r.config.Url
-    req.Method = r.config.Method
-    req.Data = []byte(r.config.Data)
-
-    for keyword, inputitem := range input {
-        req.Method = strings.Replace(req.Method, keyword, string(inputitem), -1)
-        headers := make(map[string]string, 0)
-        for h, v := range req.Headers {
-            var CanonicalHeader = textproto.CanonicalMIMEHeaderKey(strings.Replace(h, keyword, string(inputitem), -1))
-            headers[CanonicalHeader] = strings.Replace(v, keyword, string(inputitem), -1)
-        }
-        req.Headers = headers
-        req.Url = strings.Replace(req.Url, keyword, string(inputitem), -1)
-        req.Data = []byte(strings.Replace(string(req.Data), keyword, string(inputitem), -1))
-    }
-
-    req.Input = input
-    return req, nil
+    return prepareRequest(r.config, input), nil
 }
 
-/*
-TODO: Implement proxy
-*/
-
 func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
     var err error
+    start := time.Now()
     fasthttpReq := fasthttp.AcquireRequest()
     defer fasthttp.ReleaseRequest(fasthttpReq)
     fasthttpReq.Header.SetRequestURI(req.Url)
@@ -90,7 +99,12 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
     }
     // set default User-Agent header if not present
     if _, ok := req.Headers["User-Agent"]; !ok {
-        fasthttpReq.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_3) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.132 Safari/537.36")
+        fasthttpReq.Header.Set("User-Agent", DefaultUserAgent)
+    }
+
+    // Tag the replayed requests so they can be found from the proxy history
+    if r.replay && len(r.marker) == 2 {
+        fasthttpReq.Header.Set(r.marker[0], r.marker[1])
     }
 
     // Handle Go http.Request special cases
@@ -104,17 +118,19 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
 
     redirectTimes := 0
     for {
-        err = r.client.DoTimeout(fasthttpReq, fasthttpResp, time.Duration(r.config.Timeout)*time.Second)
+        err = r.do(fasthttpReq, fasthttpResp)
         if err != nil {
             if errors.Is(err, fasthttp.ErrBodyTooLarge) {
                 resp := ffuf.NewResponse(fasthttpResp, req)
+                resp.TimeToFirstByte = timeToFirstByte(start, responseFirstByte(fasthttpResp))
+                resp.Duration = time.Since(start)
                 resp.Cancelled = true
                 return resp, nil
             } else {
                 return ffuf.Response{}, err
             }
         }
-        if fasthttp.StatusCodeIsRedirect(fasthttpResp.StatusCode()) && r.config.FollowRedirects {
+        if fasthttp.StatusCodeIsRedirect(fasthttpResp.StatusCode()) && r.config.FollowRedirects && !r.replay {
             redirectTimes++
             if redirectTimes > MaxRedirectTimes {
                 return ffuf.Response{}, errors.New("too many redirects")
@@ -146,6 +162,12 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
         break
     }
     resp := ffuf.NewResponse(fasthttpResp, req)
+    resp.TimeToFirstByte = timeToFirstByte(start, responseFirstByte(fasthttpResp))
+    resp.Duration = time.Since(start)
+    if r.replay {
+        // The response of a replayed request is only interesting to the proxy
+        return resp, nil
+    }
     // Check if we should download the resource or not
     size, err := strconv.Atoi(string(fasthttpResp.Header.Peek(fasthttp.HeaderContentLength)))
     if err == nil {
@@ -171,14 +193,11 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
             return ffuf.Response{}, err
         }
     default:
-        respBody = fasthttpResp.Body()
+        // The body buffer is reused once the response is released, keep a copy of it
+        respBody = append([]byte(nil), fasthttpResp.Body()...)
     }
 
-    resp.ContentLength = int64(utf8.RuneCountInString(string(respBody)))
-    resp.Data = respBody
-
-    resp.ContentWords = int64(len(strings.Split(string(resp.Data), " ")))
-    resp.ContentLines = int64(len(strings.Split(string(resp.Data), "\n")))
+    setResponseBody(&resp, respBody)
 
     statusFormat := fmt.Sprintf("Status: %d, Size: %d, Words: %d, Lines: %d", resp.StatusCode, resp.ContentLength, resp.ContentWords, resp.ContentLines)
     if len(r.config.OutputDirectory) > 0 {
@@ -190,6 +209,25 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
     return resp, nil
 }
 
+// do sends the request either directly or through the upstream proxy
+func (r *SimpleRunner) do(req *fasthttp.Request, resp *fasthttp.Response) error {
+    timeout := time.Duration(r.config.Timeout) * time.Second
+    if r.proxyClient != nil && !bytes.Equal(req.URI().Scheme(), []byte("https")) {
+        // Plain HTTP through an HTTP proxy: keep the request line in absolute-form. The proxy
+        // credentials go on a copy, so they are not sent to the target after a redirect to https.
+        proxyReq := fasthttp.AcquireRequest()
+        defer fasthttp.ReleaseRequest(proxyReq)
+        req.CopyTo(proxyReq)
+        proxyReq.Header.SetHostBytes(req.URI().Host())
+        proxyReq.SetRequestURI(req.URI().String())
+        if len(r.proxy.auth) > 0 {
+            proxyReq.Header.Set("Proxy-Authorization", r.proxy.auth)
+        }
+        return r.proxyClient.DoTimeout(proxyReq, resp, timeout)
+    }
+    return r.client.DoTimeout(req, resp, timeout)
+}
+
 func dumpRequest(req *fasthttp.Request, statusFormat string) string {
     buf := &bytes.Buffer{}
     buf.WriteString(req.URI().String())
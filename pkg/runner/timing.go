@@ -0,0 +1,102 @@
+package runner
+
+import (
+    "net"
+    "sync"
+    "time"
+
+    "github.com/valyala/fasthttp"
+)
+
+// timingConn records when the first byte of a response arrives. Every request / response exchange
+// over the connection gets a connTiming of its own, so the timing of a pooled connection is not
+// overwritten by the requests sent over it later on.
+type timingConn struct {
+    net.Conn
+    mu      sync.Mutex
+    current *connTiming
+}
+
+type connTiming struct {
+    conn      *timingConn
+    firstByte time.Time
+}
+
+// timedAddr is returned by timingConn.LocalAddr, which fasthttp calls once per request. It carries
+// the timing of the exchange to the fasthttp.Response.
+type timedAddr struct {
+    net.Addr
+    timing *connTiming
+}
+
+func newTimingConn(conn net.Conn) *timingConn {
+    return &timingConn{Conn: conn}
+}
+
+// timedDial wraps the connections of a dial function into timingConns
+func timedDial(dial func(addr string) (net.Conn, error)) func(addr string) (net.Conn, error) {
+    return func(addr string) (net.Conn, error) {
+        conn, err := dial(addr)
+        if err != nil {
+            return conn, err
+        }
+        return newTimingConn(conn), nil
+    }
+}
+
+// startExchange starts timing a new request / response exchange
+func (c *timingConn) startExchange() *connTiming {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.current = &connTiming{conn: c}
+    return c.current
+}
+
+func (c *timingConn) Write(b []byte) (int, error) {
+    c.mu.Lock()
+    if c.current != nil {
+        // Anything read so far was a part of a handshake, not the response
+        c.current.firstByte = time.Time{}
+    }
+    c.mu.Unlock()
+    return c.Conn.Write(b)
+}
+
+func (c *timingConn) Read(b []byte) (int, error) {
+    n, err := c.Conn.Read(b)
+    if n > 0 {
+        c.mu.Lock()
+        if c.current != nil && c.current.firstByte.IsZero() {
+            c.current.firstByte = time.Now()
+        }
+        c.mu.Unlock()
+    }
+    return n, err
+}
+
+func (c *timingConn) LocalAddr() net.Addr {
+    return &timedAddr{Addr: c.Conn.LocalAddr(), timing: c.startExchange()}
+}
+
+// FirstByte returns the time the first byte of the response was read, or zero time if nothing was read
+func (t *connTiming) FirstByte() time.Time {
+    t.conn.mu.Lock()
+    defer t.conn.mu.Unlock()
+    return t.firstByte
+}
+
+// responseFirstByte returns the time the first byte of the fasthttp response was read
+func responseFirstByte(resp *fasthttp.Response) time.Time {
+    if addr, ok := resp.LocalAddr().(*timedAddr); ok {
+        return addr.timing.FirstByte()
+    }
+    return time.Time{}
+}
+
+// timeToFirstByte returns the duration from start until the first byte, or 0 if it is unknown
+func timeToFirstByte(start time.Time, firstByte time.Time) time.Duration {
+    if firstByte.IsZero() {
+        return 0
+    }
+    return firstByte.Sub(start)
+}
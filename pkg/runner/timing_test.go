@@ -0,0 +1,81 @@
+package runner
+
+import (
+    "context"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/theblackturtle/ffuf/pkg/ffuf"
+    "github.com/valyala/fasthttp"
+)
+
+func TestTimeToFirstByteKeepAlive(t *testing.T) {
+    // The headers are sent after the delay of the path, the body a while later
+    delays := map[string]time.Duration{"/fast": 20 * time.Millisecond, "/slow": 150 * time.Millisecond}
+    bodyDelay := 100 * time.Millisecond
+    var conns int32
+    srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(delays[r.URL.Path])
+        w.WriteHeader(200)
+        w.(http.Flusher).Flush()
+        time.Sleep(bodyDelay)
+        w.Write([]byte("body"))
+    }))
+    srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+        if state == http.StateNew {
+            atomic.AddInt32(&conns, 1)
+        }
+    }
+    srv.Start()
+    defer srv.Close()
+
+    conf := ffuf.NewConfig(context.Background())
+    runner := NewSimpleRunner(&conf, false)
+
+    // The timing of a response stays with it when the connection is reused by the next request
+    client := runner.(*SimpleRunner).client
+    starts := make([]time.Time, 0)
+    resps := make([]*fasthttp.Response, 0)
+    for _, path := range []string{"/slow", "/fast"} {
+        req := fasthttp.AcquireRequest()
+        defer fasthttp.ReleaseRequest(req)
+        req.SetRequestURI(srv.URL + path)
+        resp := fasthttp.AcquireResponse()
+        defer fasthttp.ReleaseResponse(resp)
+        starts = append(starts, time.Now())
+        if err := client.Do(req, resp); err != nil {
+            t.Fatalf("%s: unexpected error %s", path, err)
+        }
+        resps = append(resps, resp)
+    }
+    for i, path := range []string{"/slow", "/fast"} {
+        ttfb := timeToFirstByte(starts[i], responseFirstByte(resps[i]))
+        if delay := delays[path]; ttfb < delay || ttfb >= delay+bodyDelay {
+            t.Errorf("%s: expected the time to first byte of the pooled connection to be between %s and %s, got %s", path, delay, delay+bodyDelay, ttfb)
+        }
+    }
+
+    for _, path := range []string{"/slow", "/fast", "/slow"} {
+        req := ffuf.NewRequest(&conf)
+        req.Method = "GET"
+        req.Url = srv.URL + path
+        resp, err := runner.Execute(&req)
+        if err != nil {
+            t.Fatalf("%s: unexpected error %s", path, err)
+        }
+        delay := delays[path]
+        if resp.TimeToFirstByte < delay || resp.TimeToFirstByte >= delay+bodyDelay {
+            t.Errorf("%s: expected the time to first byte to be between %s and %s, got %s", path, delay, delay+bodyDelay, resp.TimeToFirstByte)
+        }
+        if resp.Duration < delay+bodyDelay {
+            t.Errorf("%s: expected the duration to be at least %s, got %s", path, delay+bodyDelay, resp.Duration)
+        }
+    }
+    if n := atomic.LoadInt32(&conns); n != 1 {
+        t.Errorf("Expected the requests to share a keep-alive connection, got %d connections", n)
+    }
+}